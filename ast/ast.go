@@ -1,6 +1,8 @@
 package ast
 
 import (
+	"math/big"
+
 	"github.com/alecthomas/participle/v2/lexer"
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/value"
@@ -11,10 +13,27 @@ import (
 type TypeDef struct {
 	Alias string
 	Type  *Type
+	// Constants are the members of an enum, Type is their underlying integer type
+	Constants []*EnumConstant
+}
+
+// EnumConstant is a member of an enum, its type is the alias of the enum
+type EnumConstant struct {
+	Ident string
+	// Value is nil if the member was given a value that is not an integer
+	Value *big.Int
+	Type  *Type
+
+	Scope ScopeLike
+	Pos   lexer.Position
 }
 
 type Global struct {
 	Variable *Variable
+	Expr     ExpressionLike
+
+	Scope ScopeLike
+	Pos   lexer.Position
 }
 
 type Module struct {
@@ -27,6 +46,8 @@ type Module struct {
 	Ptr          *ir.Module
 	LastID       int
 	CurrentBlock *ir.Block
+	// Warnings are reported during generation, but they don't stop it
+	Warnings []error
 
 	Scope *Scope
 	Pos   lexer.Position
@@ -43,15 +64,39 @@ type Function struct {
 	Locals []*Variable
 
 	Ptr *ir.Func
+	// innermost loop being generated
+	Loop *Loop
+	// blocks which were already reported as unreachable
+	DeadBlocks map[*ir.Block]bool
+	// labels are created by the first label or goto statement referring to them
+	Labels []*Label
 
 	Scope *Scope
 	Pos   lexer.Position
 }
 
+// Loop holds the basic blocks that break and continue jump to.
+// Loops are nested through Parent, the innermost one is stored in the Function.
+type Loop struct {
+	Break    *ir.Block
+	Continue *ir.Block
+	Parent   *Loop
+}
+
+// Label is the basic block a label statement starts. A goto can jump to it before it's defined,
+// Pos is the position of the first goto, or the label statement if that comes first.
+type Label struct {
+	Name    string
+	Block   *ir.Block
+	Defined bool
+	Pos     lexer.Position
+}
+
 // STATEMENTS
 
 type StatementLike interface {
 	String() []string
+	Position() lexer.Position
 	Generate() error
 }
 
@@ -71,15 +116,6 @@ type DeclStmt struct {
 	Pos   lexer.Position
 }
 
-type AssignStmt struct {
-	Type  *Type
-	Left  ExpressionLike
-	Right ExpressionLike
-
-	Scope ScopeLike
-	Pos   lexer.Position
-}
-
 type ReturnStmt struct {
 	Expr ExpressionLike
 
@@ -113,6 +149,64 @@ type WhileStmt struct {
 	Pos   lexer.Position
 }
 
+type DoWhileStmt struct {
+	Body      []StatementLike
+	Condition ExpressionLike
+
+	Scope ScopeLike
+	Pos   lexer.Position
+}
+
+type ForStmt struct {
+	Init      []StatementLike
+	Condition ExpressionLike
+	Post      StatementLike
+	Body      []StatementLike
+
+	Scope ScopeLike
+	Pos   lexer.Position
+}
+
+type SwitchStmt struct {
+	Expr  ExpressionLike
+	Cases []*SwitchCase
+
+	Scope ScopeLike
+	Pos   lexer.Position
+}
+
+type SwitchCase struct {
+	// Value is nil for the default case
+	Value ExpressionLike
+	Body  []StatementLike
+
+	Pos lexer.Position
+}
+
+type BreakStmt struct {
+	Scope ScopeLike
+	Pos   lexer.Position
+}
+
+type ContinueStmt struct {
+	Scope ScopeLike
+	Pos   lexer.Position
+}
+
+type LabelStmt struct {
+	Name string
+
+	Scope ScopeLike
+	Pos   lexer.Position
+}
+
+type GotoStmt struct {
+	Name string
+
+	Scope ScopeLike
+	Pos   lexer.Position
+}
+
 // EXPRESSIONS
 
 type Value struct {
@@ -123,9 +217,20 @@ type Value struct {
 
 type ExpressionLike interface {
 	String() string
+	Position() lexer.Position
 	Value() (*Value, error)
 }
 
+// ContextualLike expressions don't have a fixed type on their own (e.g. number literals),
+// instead they adopt the type their context expects, if they can
+type ContextualLike interface {
+	ExpressionLike
+	// ValueAs returns the value as typ if possible, otherwise it returns the same as Value
+	ValueAs(typ *Type) (*Value, error)
+	// IsUntyped is true, if the type of the value depends only on its context
+	IsUntyped() bool
+}
+
 type BinaryOp struct {
 	Left  ExpressionLike
 	Op    string
@@ -135,6 +240,25 @@ type BinaryOp struct {
 	Pos   lexer.Position
 }
 
+// AssignOp is an expression, so assignments can be chained, e.g. a = b = 0
+type AssignOp struct {
+	Left  ExpressionLike
+	Op    string
+	Right ExpressionLike
+
+	Scope ScopeLike
+	Pos   lexer.Position
+}
+
+type ConditionalOp struct {
+	Condition ExpressionLike
+	Then      ExpressionLike
+	Else      ExpressionLike
+
+	Scope ScopeLike
+	Pos   lexer.Position
+}
+
 type UnaryOp struct {
 	Op        string
 	Expr      ExpressionLike
@@ -170,9 +294,41 @@ type CastingOp struct {
 	Pos   lexer.Position
 }
 
+type StructLiteralField struct {
+	Ident string
+	Expr  ExpressionLike
+
+	Pos lexer.Position
+}
+
+type StructLiteralOp struct {
+	Type   *Type
+	Fields []*StructLiteralField
+
+	Scope ScopeLike
+	Pos   lexer.Position
+}
+
+type InitializerElement struct {
+	// Index is set for designated elements, e.g. [2] = x
+	Index ExpressionLike
+	Expr  ExpressionLike
+
+	Pos lexer.Position
+}
+
+type InitializerListOp struct {
+	Elements []*InitializerElement
+
+	Scope ScopeLike
+	Pos   lexer.Position
+}
+
 type FnCallOp struct {
 	Ident string
-	Args  []ExpressionLike
+	// Callee is used instead of Ident for calls through an expression, e.g. table[i](x)
+	Callee ExpressionLike
+	Args   []ExpressionLike
 
 	Scope ScopeLike
 	Pos   lexer.Position
@@ -200,6 +356,12 @@ type ConstantBoolOp struct {
 	Pos   lexer.Position
 }
 
+// ConstantNullOp is the null pointer, it adopts the pointer type of its context
+type ConstantNullOp struct {
+	Scope ScopeLike
+	Pos   lexer.Position
+}
+
 type ConstantNumberOp struct {
 	Sign     string
 	Constant string
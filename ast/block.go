@@ -3,6 +3,8 @@ package ast
 import (
 	"fmt"
 
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/klvnptr/k/utils"
 	"github.com/llir/llvm/ir"
 )
 
@@ -87,8 +89,52 @@ func (b *Block) SetBasicBlock(bb *ir.Block) {
 	b.CurrentModule().SetBasicBlock(bb)
 }
 
+func (b *Block) CurrentLoop() *Loop {
+	return b.Scope.Parent.CurrentLoop()
+}
+
+func (b *Block) SetCurrentLoop(l *Loop) {
+	b.Scope.Parent.SetCurrentLoop(l)
+}
+
+func (b *Block) Position() lexer.Position {
+	return b.Pos
+}
+
 func (b *Block) Generate() error {
-	for _, stmt := range b.Stmts {
+	return generateStatements(b, b.Stmts)
+}
+
+// generateStatements generates stmts into the current basic block. Statements that can never run
+// (e.g. the ones after a return) get a warning, and they are generated into a new block that nothing
+// jumps to, because instructions can't be added to a block after its terminator.
+func generateStatements(scope ScopeLike, stmts []StatementLike) error {
+	fn := scope.CurrentFunction()
+
+	for _, stmt := range stmts {
+		// labels start a new block, which can be jumped to
+		if _, ok := stmt.(*LabelStmt); ok {
+			if err := stmt.Generate(); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if bb := scope.BasicBlock(); fn.isDead(bb) {
+			// statements in a block that was already reported are not reported again
+			if !fn.DeadBlocks[bb] {
+				scope.CurrentModule().Warn(utils.WithPos(fmt.Errorf("unreachable code"), scope.Current().File, stmt.Position()))
+			}
+
+			if bb.Term != nil {
+				bb = fn.Ptr.NewBlock(scope.CurrentModule().GenerateID("unreachable"))
+				scope.SetBasicBlock(bb)
+			}
+
+			fn.DeadBlocks[bb] = true
+		}
+
 		if err := stmt.Generate(); err != nil {
 			return err
 		}
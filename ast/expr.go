@@ -2,8 +2,12 @@ package ast
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
+	"strings"
 
+	"github.com/alecthomas/participle/v2/lexer"
 	"github.com/klvnptr/k/utils"
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/constant"
@@ -16,22 +20,309 @@ func (b *BinaryOp) String() string {
 	return fmt.Sprintf("%s %s %s", b.Left.String(), b.Op, b.Right.String())
 }
 
+func (b *BinaryOp) Position() lexer.Position {
+	return b.Pos
+}
+
 func (b *BinaryOp) Value() (*Value, error) {
+	logical := b.Op == "&&" || b.Op == "||"
+	shift := b.Op == "<<" || b.Op == ">>"
+
+	if b.IsUntyped() {
+		return b.ValueAs(nil)
+	}
+
+	// an untyped constant on the left adopts the type of the right operand, e.g. 1 + x
+	// it has no side effects, so it's fine to evaluate the right operand first, but b ? 1 : 2 is evaluated in order
+	// the type of a shift doesn't depend on the shift count, so those are left alone
+	if !logical && !shift && isConstant(b.Left) && !isUntyped(b.Right) {
+		right, err := b.Right.Value()
+		if err != nil {
+			return nil, err
+		}
+
+		left, err := valueAs(b.Left, right.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		return b.apply(left, right)
+	}
+
 	left, err := b.Left.Value()
 	if err != nil {
 		return nil, err
 	}
 
+	if logical && left.Type.IsBool() {
+		return b.shortCircuit(left)
+	}
+
+	right, err := valueAs(b.Right, left.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.apply(left, right)
+}
+
+// IsUntyped is true for arithmetic on untyped constants only, e.g. 1 + 2
+func (b *BinaryOp) IsUntyped() bool {
+	switch b.Op {
+	case "+", "-", "*", "/", "%", "&", "|", "^", "<<", ">>":
+		return isUntyped(b.Left) && isUntyped(b.Right)
+	default:
+		return false
+	}
+}
+
+// ValueAs folds arithmetic on untyped numbers, and converts the result to the expected type, e.g. i8 x = 1 + 2;
+// Otherwise it passes the expected type down to the untyped operands, e.g. i8 x = (b ? 1 : 2) + 3;
+func (b *BinaryOp) ValueAs(typ *Type) (*Value, error) {
+	if !b.IsUntyped() {
+		return b.Value()
+	}
+
+	i, f, ok, err := b.fold()
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		number := &ConstantNumberOp{
+			Constant: b.String(),
+			Scope:    b.Scope,
+			Pos:      b.Pos,
+		}
+
+		return number.as(typ, i, f)
+	}
+
+	left, err := valueAs(b.Left, typ)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := valueAs(b.Right, left.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.apply(left, right)
+}
+
+// fold evaluates arithmetic on untyped numbers exactly, the result is range checked only once it gets a type.
+// If both operands are integers, the result is an integer too, e.g. 1 / 2 is 0 even in f64 x = 1 / 2;
+// It returns false if an operand is not a number, e.g. b ? 1 : 2, or the operation isn't defined on floats.
+func (b *BinaryOp) fold() (*big.Int, float64, bool, error) {
+	li, lf, ok, err := foldNumber(b.Left)
+	if !ok || err != nil {
+		return nil, 0, ok, err
+	}
+
+	ri, rf, ok, err := foldNumber(b.Right)
+	if !ok || err != nil {
+		return nil, 0, ok, err
+	}
+
+	if li != nil && ri != nil {
+		result := new(big.Int)
+
+		switch b.Op {
+		case "+":
+			result.Add(li, ri)
+		case "-":
+			result.Sub(li, ri)
+		case "*":
+			result.Mul(li, ri)
+		case "/", "%":
+			if ri.Sign() == 0 {
+				return nil, 0, false, utils.WithPos(fmt.Errorf("division by zero"), b.Scope.Current().File, b.Pos)
+			}
+
+			// both truncate towards zero, like in C
+			if b.Op == "/" {
+				result.Quo(li, ri)
+			} else {
+				result.Rem(li, ri)
+			}
+		case "&":
+			result.And(li, ri)
+		case "|":
+			result.Or(li, ri)
+		case "^":
+			result.Xor(li, ri)
+		case "<<", ">>":
+			// no type is wider than 64 bits, so larger shift counts can only overflow
+			if ri.Sign() < 0 || ri.Cmp(big.NewInt(64)) > 0 {
+				return nil, 0, false, utils.WithPos(fmt.Errorf("invalid shift count %s", ri.String()), b.Scope.Current().File, b.Pos)
+			}
+
+			if b.Op == "<<" {
+				result.Lsh(li, uint(ri.Uint64()))
+			} else {
+				result.Rsh(li, uint(ri.Uint64()))
+			}
+		}
+
+		return result, 0, true, nil
+	}
+
+	if li != nil {
+		lf, _ = new(big.Float).SetInt(li).Float64()
+	}
+
+	if ri != nil {
+		rf, _ = new(big.Float).SetInt(ri).Float64()
+	}
+
+	switch b.Op {
+	case "+":
+		return nil, lf + rf, true, nil
+	case "-":
+		return nil, lf - rf, true, nil
+	case "*":
+		return nil, lf * rf, true, nil
+	case "/":
+		if rf == 0 {
+			return nil, 0, false, utils.WithPos(fmt.Errorf("division by zero"), b.Scope.Current().File, b.Pos)
+		}
+
+		return nil, lf / rf, true, nil
+	default:
+		return nil, 0, false, nil
+	}
+}
+
+// shortCircuit evaluates the right operand only if the left one doesn't decide the result already
+func (b *BinaryOp) shortCircuit(left *Value) (*Value, error) {
+	fn := b.Scope.CurrentFunction()
+	if fn == nil {
+		// outside of functions (e.g. global initializers) there are no side effects to skip
+		right, err := b.Right.Value()
+		if err != nil {
+			return nil, err
+		}
+
+		return b.apply(left, right)
+	}
+
+	prefix := "and"
+	if b.Op == "||" {
+		prefix = "or"
+	}
+
+	rhsBlock := fn.Ptr.NewBlock(b.Scope.CurrentModule().GenerateID(prefix + ".rhs"))
+	mergeBlock := fn.Ptr.NewBlock(b.Scope.CurrentModule().GenerateID(prefix + ".merge"))
+
+	// the block might have changed while evaluating the left operand (e.g. nested && and ||)
+	leftBlock := b.Scope.BasicBlock()
+	if b.Op == "&&" {
+		leftBlock.NewCondBr(left.Value, rhsBlock, mergeBlock)
+	} else {
+		leftBlock.NewCondBr(left.Value, mergeBlock, rhsBlock)
+	}
+
+	b.Scope.SetBasicBlock(rhsBlock)
+
 	right, err := b.Right.Value()
 	if err != nil {
 		return nil, err
 	}
 
+	if !left.Type.Equals(right.Type) {
+		return nil, utils.WithPos(fmt.Errorf("incompatible types %s and %s", left.Type.String(), right.Type.String()), b.Scope.Current().File, b.Pos)
+	}
+
+	rightBlock := b.Scope.BasicBlock()
+	rightBlock.NewBr(mergeBlock)
+
+	b.Scope.SetBasicBlock(mergeBlock)
+
+	// if we skipped the right operand, the result is the left operand: false for && and true for ||
+	result := mergeBlock.NewPhi(ir.NewIncoming(left.Value, leftBlock), ir.NewIncoming(right.Value, rightBlock))
+
+	return &Value{
+		Type:  NewTypeBasic(b.Scope, b.Pos, BasicTypeBool),
+		Value: result,
+	}, nil
+}
+
+// apply generates the operation on already evaluated operands
+func (b *BinaryOp) apply(left, right *Value) (*Value, error) {
+	shift := b.Op == "<<" || b.Op == ">>"
+
+	// operands of different arithmetic types are converted to their common type first
+	if !shift && !left.Type.Equals(right.Type) && left.Type.isArithmetic() && right.Type.isArithmetic() {
+		typ := promote(left.Type, right.Type)
+		if typ == nil {
+			return nil, utils.WithPos(fmt.Errorf("incompatible types %s and %s, use an explicit cast", left.Type.String(), right.Type.String()), b.Scope.Current().File, b.Pos)
+		}
+
+		var err error
+
+		left, err = cast(b.Scope, b.Pos, left, typ)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err = cast(b.Scope, b.Pos, right, typ)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// void* can be compared with any other pointer
+	if !left.Type.Equals(right.Type) && left.Type.IsPointer() && right.Type.IsPointer() {
+		var err error
+
+		if left.Type.IsVoidPointer() {
+			right, err = convert(b.Scope, right, left.Type)
+		} else {
+			left, err = convert(b.Scope, left, right.Type)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// dividing by a constant zero is undefined, so it's rejected like in untyped constants, e.g. (i64)7 / (i64)0
+	if (b.Op == "/" || b.Op == "%") && (right.Type.IsInt() || right.Type.IsUInt()) {
+		if c, ok := right.Value.(*constant.Int); ok && c.X.Sign() == 0 {
+			return nil, utils.WithPos(fmt.Errorf("division by zero"), b.Scope.Current().File, b.Pos)
+		}
+	}
+
 	bb := b.Scope.BasicBlock()
 	var result value.Value
 
 	// if eithe left or right is a pointer, we need to do some pointer arithmetic
-	if left.Type.IsPointer() && !right.Type.IsPointer() && right.Type.IsInt() {
+	if shift && (left.Type.IsInt() || left.Type.IsUInt()) && (right.Type.IsInt() || right.Type.IsUInt()) {
+		// the shift count can be of any integer type, but llvm expects it to be the same width as the shifted value
+		count := right.Value
+		leftSize, rightSize := left.Type.LLVMIntType().BitSize, right.Type.LLVMIntType().BitSize
+
+		if rightSize > leftSize {
+			count = bb.NewTrunc(count, left.Type.LLVMIntType())
+		} else if rightSize < leftSize && right.Type.IsInt() {
+			count = bb.NewSExt(count, left.Type.LLVMIntType())
+		} else if rightSize < leftSize {
+			count = bb.NewZExt(count, left.Type.LLVMIntType())
+		}
+
+		switch {
+		case b.Op == "<<":
+			result = bb.NewShl(left.Value, count)
+		case left.Type.IsInt():
+			// arithmetic shift keeps the sign bit
+			result = bb.NewAShr(left.Value, count)
+		default:
+			result = bb.NewLShr(left.Value, count)
+		}
+	} else if (left.Type.IsVoidPointer() || right.Type.IsVoidPointer()) && (b.Op == "+" || b.Op == "-") {
+		return nil, utils.WithPos(fmt.Errorf("cannot do arithmetic on void*"), b.Scope.Current().File, b.Pos)
+	} else if left.Type.IsPointer() && !right.Type.IsPointer() && right.Type.IsInt() {
 		ptrIRType, err := left.Type.Pointer().IRType()
 		if err != nil {
 			return nil, err
@@ -177,6 +468,13 @@ func (b *BinaryOp) Value() (*Value, error) {
 		case "%":
 			result = bb.NewFRem(left.Value, right.Value)
 		}
+	} else if left.Type.IsFunc() {
+		switch b.Op {
+		case "==":
+			result = bb.NewICmp(enum.IPredEQ, left.Value, right.Value)
+		case "!=":
+			result = bb.NewICmp(enum.IPredNE, left.Value, right.Value)
+		}
 	} else if left.Type.IsPointer() {
 		switch b.Op {
 		case "==":
@@ -191,6 +489,8 @@ func (b *BinaryOp) Value() (*Value, error) {
 			result = bb.NewICmp(enum.IPredULE, left.Value, right.Value)
 		case ">=":
 			result = bb.NewICmp(enum.IPredUGE, left.Value, right.Value)
+		case "-":
+			return b.distance(left, right)
 		}
 	}
 
@@ -198,6 +498,8 @@ func (b *BinaryOp) Value() (*Value, error) {
 		return nil, utils.WithPos(fmt.Errorf("operation %s is not implemented for %s", b.Op, left.Type), b.Scope.Current().File, b.Pos)
 	}
 
+	result = foldLL(b.Scope, result)
+
 	// if result is a boolean, we can't use left's type, we need to use a bool
 	if result.Type().Equal(NewLLTypeBool()) {
 		return &Value{
@@ -212,10 +514,246 @@ func (b *BinaryOp) Value() (*Value, error) {
 	}, nil
 }
 
+// distance returns the number of elements between two pointers of the same type, e.g. &x[3] - &x[1] is 2
+func (b *BinaryOp) distance(left, right *Value) (*Value, error) {
+	elemIRType, err := left.Type.Pointer().IRType()
+	if err != nil {
+		return nil, err
+	}
+
+	bb := b.Scope.BasicBlock()
+
+	diff := bb.NewSub(bb.NewPtrToInt(left.Value, types.I64), bb.NewPtrToInt(right.Value, types.I64))
+
+	// the size of an element, computed the same way as sizeof
+	size := constant.NewPtrToInt(
+		constant.NewGetElementPtr(elemIRType, constant.NewNull(types.NewPointer(elemIRType)), NewLLInt(32, 1)),
+		types.I64,
+	)
+
+	// the pointers point into the same array, so the difference is always a multiple of the size
+	result := bb.NewSDiv(diff, size)
+	result.Exact = true
+
+	return &Value{
+		Type:  NewTypeBasic(b.Scope, b.Pos, BasicTypeI64),
+		Value: result,
+	}, nil
+}
+
+func (a *AssignOp) String() string {
+	return fmt.Sprintf("%s %s %s", a.Left.String(), a.Op, a.Right.String())
+}
+
+func (a *AssignOp) Position() lexer.Position {
+	return a.Pos
+}
+
+// Value stores the right operand into the left one, and returns the stored value
+func (a *AssignOp) Value() (*Value, error) {
+	left, err := a.Left.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := valueAs(a.Right, left.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if left.Ptr == nil {
+		return nil, utils.WithPos(fmt.Errorf("cannot assign to non-variable"), a.Scope.Current().File, a.Pos)
+	}
+
+	// compound assignments (a += b) are lowered to a = a + b, but the target is evaluated only once,
+	// we reuse its pointer instead
+	if a.Op != "=" {
+		irType, err := left.Type.IRType()
+		if err != nil {
+			return nil, err
+		}
+
+		// load the value again, right might have modified it
+		current := &Value{
+			Type:  left.Type,
+			Ptr:   left.Ptr,
+			Value: a.Scope.BasicBlock().NewLoad(irType, left.Ptr),
+		}
+
+		op := &BinaryOp{
+			Op:    strings.TrimSuffix(a.Op, "="),
+			Scope: a.Scope,
+			Pos:   a.Pos,
+		}
+
+		right, err = op.apply(current, right)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	right, err = convert(a.Scope, right, left.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if !left.Type.Equals(right.Type) {
+		return nil, utils.WithPos(fmt.Errorf("cannot assign %s to %s", right.Type.String(), left.Type.String()), a.Scope.Current().File, a.Pos)
+	}
+
+	a.Scope.BasicBlock().NewStore(right.Value, left.Ptr)
+
+	// the result is not an lvalue, like in C
+	return &Value{
+		Type:  left.Type,
+		Value: right.Value,
+	}, nil
+}
+
+func (c *ConditionalOp) String() string {
+	return fmt.Sprintf("%s ? %s : %s", c.Condition.String(), c.Then.String(), c.Else.String())
+}
+
+func (c *ConditionalOp) Position() lexer.Position {
+	return c.Pos
+}
+
+func (c *ConditionalOp) Value() (*Value, error) {
+	return c.generate(nil)
+}
+
+// IsUntyped is true if both arms are untyped constants, e.g. b ? 1 : 2
+func (c *ConditionalOp) IsUntyped() bool {
+	return isUntyped(c.Then) && isUntyped(c.Else)
+}
+
+// ValueAs passes the expected type down to untyped arms, e.g. i8 x = b ? 1 : 2;
+func (c *ConditionalOp) ValueAs(typ *Type) (*Value, error) {
+	if !c.IsUntyped() {
+		return c.Value()
+	}
+
+	return c.generate(typ)
+}
+
+// generate evaluates only the chosen arm, and merges the results with a phi
+// if typ is nil, the type of the result is the common type of the arms
+func (c *ConditionalOp) generate(typ *Type) (*Value, error) {
+	cond, err := c.Condition.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	if !cond.Type.IsBool() {
+		return nil, utils.WithPos(fmt.Errorf("cannot use %s as condition", cond.Type.String()), c.Scope.Current().File, c.Pos)
+	}
+
+	fn := c.Scope.CurrentFunction()
+	if fn == nil {
+		// outside of functions (e.g. global initializers) we can't branch, but we can pick the arm right away
+		k, ok := cond.Value.(*constant.Int)
+		if !ok {
+			return nil, utils.WithPos(fmt.Errorf("condition must be constant outside of functions"), c.Scope.Current().File, c.Pos)
+		}
+
+		arm := c.Else
+		if k.X.Sign() != 0 {
+			arm = c.Then
+		}
+
+		if typ != nil {
+			return valueAs(arm, typ)
+		}
+
+		return arm.Value()
+	}
+
+	thenBlock := fn.Ptr.NewBlock(c.Scope.CurrentModule().GenerateID("cond.then"))
+	elseBlock := fn.Ptr.NewBlock(c.Scope.CurrentModule().GenerateID("cond.else"))
+	mergeBlock := fn.Ptr.NewBlock(c.Scope.CurrentModule().GenerateID("cond.merge"))
+
+	c.Scope.BasicBlock().NewCondBr(cond.Value, thenBlock, elseBlock)
+
+	arms := []ExpressionLike{c.Then, c.Else}
+	blocks := []*ir.Block{thenBlock, elseBlock}
+
+	// an untyped arm adopts the type of the other one, e.g. b ? x : 1
+	// the arms are in different blocks, so it's fine to generate the typed one first
+	order := []int{0, 1}
+	if typ == nil && isUntyped(c.Then) && !isUntyped(c.Else) {
+		order = []int{1, 0}
+	}
+
+	values := make([]*Value, 2)
+
+	for n, i := range order {
+		c.Scope.SetBasicBlock(blocks[i])
+
+		expected := typ
+		if expected == nil && n == 1 {
+			expected = values[order[0]].Type
+		}
+
+		if expected != nil {
+			values[i], err = valueAs(arms[i], expected)
+		} else {
+			values[i], err = arms[i].Value()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// the arm might have ended in a different block (e.g. nested conditionals)
+		blocks[i] = c.Scope.BasicBlock()
+	}
+
+	resultType := values[0].Type
+	if !values[0].Type.Equals(values[1].Type) {
+		resultType = promote(values[0].Type, values[1].Type)
+		if resultType == nil {
+			return nil, utils.WithPos(fmt.Errorf("incompatible types %s and %s in conditional expression", values[0].Type.String(), values[1].Type.String()), c.Scope.Current().File, c.Pos)
+		}
+	}
+
+	// the conversions must happen in the arms, before branching to the merge block
+	for i := range values {
+		c.Scope.SetBasicBlock(blocks[i])
+
+		if !values[i].Type.Equals(resultType) {
+			values[i], err = cast(c.Scope, c.Pos, values[i], resultType)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		c.Scope.BasicBlock().NewBr(mergeBlock)
+		blocks[i] = c.Scope.BasicBlock()
+	}
+
+	c.Scope.SetBasicBlock(mergeBlock)
+
+	// void arms, e.g. b ? f() : g(), don't have a value to merge
+	if resultType.IsVoid() {
+		return &Value{Type: resultType}, nil
+	}
+
+	result := mergeBlock.NewPhi(ir.NewIncoming(values[0].Value, blocks[0]), ir.NewIncoming(values[1].Value, blocks[1]))
+
+	return &Value{
+		Type:  resultType,
+		Value: result,
+	}, nil
+}
+
 func (u *UnaryOp) String() string {
 	return fmt.Sprintf("%s %s", u.Op, u.Expr.String())
 }
 
+func (u *UnaryOp) Position() lexer.Position {
+	return u.Pos
+}
+
 func (u *UnaryOp) Value() (*Value, error) {
 	original, err := u.Expr.Value()
 
@@ -239,6 +777,10 @@ func (u *UnaryOp) Value() (*Value, error) {
 		// load the value, just in case in has been modified
 		original.Value = u.Scope.BasicBlock().NewLoad(irType, original.Ptr)
 
+		if original.Type.IsVoidPointer() {
+			return nil, utils.WithPos(fmt.Errorf("cannot do arithmetic on void*"), u.Scope.Current().File, u.Pos)
+		}
+
 		if original.Type.IsPointer() {
 			ptrIRType, err := original.Type.Pointer().IRType()
 			if err != nil {
@@ -307,7 +849,7 @@ func (u *UnaryOp) Value() (*Value, error) {
 			return nil, utils.WithPos(fmt.Errorf("cannot negate a %s", original.Type.String()), u.Scope.Current().File, u.Pos)
 		}
 
-		return &Value{Type: original.Type, Value: result}, nil
+		return &Value{Type: original.Type, Value: foldLL(u.Scope, result)}, nil
 	case "!":
 		if original.Type.IsInt() || original.Type.IsUInt() {
 			result = u.Scope.BasicBlock().NewICmp(enum.IPredEQ, original.Value, constant.NewInt(original.Type.LLVMIntType(), 0))
@@ -317,18 +859,32 @@ func (u *UnaryOp) Value() (*Value, error) {
 			return nil, utils.WithPos(fmt.Errorf("cannot negate a %s", original.Type.String()), u.Scope.Current().File, u.Pos)
 		}
 
-		return &Value{Type: original.Type, Value: result}, nil
+		return &Value{Type: original.Type, Value: foldLL(u.Scope, result)}, nil
 	case "&":
+		// like in C, a function and its address are the same, e.g. &cmp
+		if original.Type.IsFunc() && original.Ptr == nil {
+			return original, nil
+		}
+
 		if original.Ptr == nil {
 			return nil, utils.WithPos(fmt.Errorf("cannot take the address of a non-variable"), u.Scope.Current().File, u.Pos)
 		}
 
 		return &Value{Type: original.Type.NewPointer(), Value: original.Ptr}, nil
 	case "*":
+		// and dereferencing a function pointer gives the function, e.g. (*fp)(x)
+		if original.Type.IsFunc() {
+			return &Value{Type: original.Type, Value: original.Value}, nil
+		}
+
 		if !original.Type.IsPointer() {
 			return nil, utils.WithPos(fmt.Errorf("cannot dereference a non-pointer type"), u.Scope.Current().File, u.Pos)
 		}
 
+		if original.Type.IsVoidPointer() {
+			return nil, utils.WithPos(fmt.Errorf("cannot dereference void*"), u.Scope.Current().File, u.Pos)
+		}
+
 		ptr := original.Type.Pointer()
 
 		ptrIRType, err := ptr.IRType()
@@ -350,6 +906,10 @@ func (ao *AccessorOp) String() string {
 	return fmt.Sprintf("%s.%s", ao.Expr.String(), ao.Field)
 }
 
+func (ao *AccessorOp) Position() lexer.Position {
+	return ao.Pos
+}
+
 func (ao *AccessorOp) Value() (*Value, error) {
 	expr, err := ao.Expr.Value()
 
@@ -394,13 +954,20 @@ func (ao *AccessorOp) Value() (*Value, error) {
 		return nil, err
 	}
 
-	ptr := ao.Scope.BasicBlock().NewGetElementPtr(exprIRType, expr.Ptr, NewLLInt(32, 0), NewLLInt(32, index))
-
 	fieldIRType, err := field.Type.IRType()
 	if err != nil {
 		return nil, err
 	}
 
+	var ptr value.Value
+
+	// every field of a union starts at its beginning
+	if expr.Type.Struct().Union {
+		ptr = ao.Scope.BasicBlock().NewBitCast(expr.Ptr, types.NewPointer(fieldIRType))
+	} else {
+		ptr = ao.Scope.BasicBlock().NewGetElementPtr(exprIRType, expr.Ptr, NewLLInt(32, 0), NewLLInt(32, index))
+	}
+
 	loaded := ao.Scope.BasicBlock().NewLoad(fieldIRType, ptr)
 
 	return &Value{
@@ -414,6 +981,10 @@ func (io *IndexOp) String() string {
 	return fmt.Sprintf("%s[%s]", io.Expr.String(), io.IndexExpr.String())
 }
 
+func (io *IndexOp) Position() lexer.Position {
+	return io.Pos
+}
+
 func (io *IndexOp) Value() (*Value, error) {
 	expr, err := io.Expr.Value()
 
@@ -433,6 +1004,10 @@ func (io *IndexOp) Value() (*Value, error) {
 
 	bb := io.Scope.BasicBlock()
 
+	if expr.Type.IsVoidPointer() {
+		return nil, utils.WithPos(fmt.Errorf("cannot dereference void*"), io.Scope.Current().File, io.Pos)
+	}
+
 	if expr.Type.IsPointer() {
 		ptr := expr.Type.Pointer()
 
@@ -485,126 +1060,262 @@ func (c *CastingOp) String() string {
 	return "(" + c.Type.String() + ")" + c.Expr.String()
 }
 
+func (c *CastingOp) Position() lexer.Position {
+	return c.Pos
+}
+
 func (c *CastingOp) Value() (*Value, error) {
 	expr, err := c.Expr.Value()
 	if err != nil {
 		return nil, err
 	}
 
-	if expr.Type.Equals(c.Type) {
-		return &Value{Type: c.Type, Ptr: expr.Ptr, Value: expr.Value}, nil
-	}
+	return cast(c.Scope, c.Pos, expr, c.Type)
+}
 
-	if expr.Type.AliasOf(c.Type) {
-		return &Value{Type: c.Type, Value: expr.Value}, nil
+func (sl *StructLiteralOp) String() string {
+	fields := []string{}
+	for _, f := range sl.Fields {
+		fields = append(fields, fmt.Sprintf(".%s = %s", f.Ident, f.Expr.String()))
 	}
 
-	var result value.Value
+	return fmt.Sprintf("%s{ %s, }", sl.Type.String(), strings.Join(fields, ", "))
+}
+
+func (sl *StructLiteralOp) Position() lexer.Position {
+	return sl.Pos
+}
 
-	targetIRType, err := c.Type.IRType()
+func (sl *StructLiteralOp) Value() (*Value, error) {
+	// also makes sure that the alias exists
+	irType, err := sl.Type.IRType()
 	if err != nil {
 		return nil, err
 	}
 
-	if c.Type.IsInt() {
-		if expr.Type.IsInt() || expr.Type.IsUInt() {
-			if c.Type.BasicSize() > expr.Type.BasicSize() {
-				result = c.Scope.BasicBlock().NewSExt(expr.Value, targetIRType)
-			} else if c.Type.BasicSize() < expr.Type.BasicSize() {
-				result = c.Scope.BasicBlock().NewTrunc(expr.Value, targetIRType)
-			} else {
-				result = expr.Value
-			}
-		} else if expr.Type.IsFloat() {
-			result = c.Scope.BasicBlock().NewFPToSI(expr.Value, targetIRType)
-		}
-	} else if c.Type.IsUInt() {
-		if expr.Type.IsInt() || expr.Type.IsUInt() {
-			if c.Type.BasicSize() > expr.Type.BasicSize() {
-				result = c.Scope.BasicBlock().NewZExt(expr.Value, targetIRType)
-			} else if c.Type.BasicSize() < expr.Type.BasicSize() {
-				result = c.Scope.BasicBlock().NewTrunc(expr.Value, targetIRType)
-			} else {
-				result = expr.Value
-			}
-		} else if expr.Type.IsFloat() {
-			result = c.Scope.BasicBlock().NewFPToUI(expr.Value, targetIRType)
+	if !sl.Type.IsStruct() || sl.Type.Struct().Union {
+		return nil, utils.WithPos(fmt.Errorf("cannot use struct literal for non-struct type %s", sl.Type.String()), sl.Scope.Current().File, sl.Pos)
+	}
+
+	st := sl.Type.Struct()
+
+	// omitted fields are zero, that's why we start with a zero initializer
+	values := make([]value.Value, len(st.Fields))
+	allConstant := true
+
+	for _, f := range sl.Fields {
+		index, field, err := st.FindField(f.Ident)
+		if err != nil {
+			return nil, utils.WithPos(err, sl.Scope.Current().File, f.Pos)
 		}
-	} else if c.Type.IsFloat() && expr.Type.IsFloat() {
-		if c.Type.BasicSize() > expr.Type.BasicSize() {
-			result = c.Scope.BasicBlock().NewFPExt(expr.Value, targetIRType)
-		} else {
-			result = c.Scope.BasicBlock().NewFPTrunc(expr.Value, targetIRType)
-		}
-	} else if c.Type.IsPointer() {
-		if expr.Type.IsPointer() {
-			result = c.Scope.BasicBlock().NewBitCast(expr.Value, targetIRType)
-		} else if expr.Type.IsArray() {
-			if expr.Ptr == nil {
-				return nil, utils.WithPos(fmt.Errorf("cannot cast a non-variable array to a pointer"), c.Scope.Current().File, c.Pos)
-			}
 
-			// elemIRType, err := expr.Type.Array().Type.IRType()
-			irType, err := expr.Type.IRType()
-			if err != nil {
-				return nil, err
-			}
+		if values[index] != nil {
+			return nil, utils.WithPos(fmt.Errorf("field '%s' is initialized more than once", f.Ident), sl.Scope.Current().File, f.Pos)
+		}
+
+		expr, err := valueAs(f.Expr, field.Type)
+		if err != nil {
+			return nil, err
+		}
 
-			result = c.Scope.BasicBlock().NewGetElementPtr(irType, expr.Ptr, NewLLInt(32, 0), NewLLInt(32, 0))
-			// result = c.Scope.BasicBlock().NewBitCast(expr.Value, types.NewPointer(elemIRType))
+		expr, err = convert(sl.Scope, expr, field.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		if !expr.Type.Equals(field.Type) {
+			return nil, utils.WithPos(fmt.Errorf("cannot assign %s to field '%s' of type %s", expr.Type.String(), f.Ident, field.Type.String()), sl.Scope.Current().File, f.Pos)
 		}
+
+		if _, ok := expr.Value.(constant.Constant); !ok {
+			allConstant = false
+		}
+
+		values[index] = expr.Value
 	}
 
-	if result == nil {
-		return nil, utils.WithPos(fmt.Errorf("casting from %s to %s not implemented", expr.Type.String(), c.Type.String()), c.Scope.Current().File, c.Pos)
+	// if every field is a constant, the whole literal can be a constant, e.g. to initialize globals
+	if allConstant {
+		fields := make([]constant.Constant, len(st.Fields))
+
+		for i, v := range values {
+			if v == nil {
+				fieldIRType, err := st.Fields[i].Type.IRType()
+				if err != nil {
+					return nil, err
+				}
+
+				fields[i] = constant.NewZeroInitializer(fieldIRType)
+			} else {
+				fields[i] = v.(constant.Constant)
+			}
+		}
+
+		return &Value{
+			Type:  sl.Type,
+			Value: constant.NewStruct(irType.(*types.StructType), fields...),
+		}, nil
+	}
+
+	var result value.Value = constant.NewZeroInitializer(irType)
+
+	for i, v := range values {
+		if v != nil {
+			result = sl.Scope.BasicBlock().NewInsertValue(result, v, uint64(i))
+		}
 	}
 
 	return &Value{
-		Type:  c.Type,
+		Type:  sl.Type,
 		Value: result,
 	}, nil
-
 }
 
 func (f *FnCallOp) String() string {
+	if f.Callee != nil {
+		return fmt.Sprintf("%s(%s)", f.Callee.String(), ExpressionLikeList(f.Args).String())
+	}
+
 	return fmt.Sprintf("%s(%s)", f.Ident, ExpressionLikeList(f.Args).String())
 }
 
+func (f *FnCallOp) Position() lexer.Position {
+	return f.Pos
+}
+
 func (f *FnCallOp) Value() (*Value, error) {
-	fn := f.Scope.FindFunction(f.Ident)
+	callee := f.Callee
+	name := f.Ident
 
-	if fn == nil {
-		return nil, utils.WithPos(fmt.Errorf("function %s not found", f.Ident), f.Scope.Current().File, f.Pos)
+	if callee == nil {
+		fn := f.Scope.FindFunction(f.Ident)
+
+		if fn != nil {
+			names := []string{}
+			for _, p := range fn.Params {
+				names = append(names, p.Ident)
+			}
+
+			return f.call(fn.Ptr, fn.Type(), fn.Name, names)
+		}
+
+		// variables can hold functions too
+		if f.Scope.FindVariable(f.Ident) == nil {
+			return nil, utils.WithPos(fmt.Errorf("function %s not found", f.Ident), f.Scope.Current().File, f.Pos)
+		}
+
+		callee = &LoadOp{
+			Name:  f.Ident,
+			Scope: f.Scope,
+			Pos:   f.Pos,
+		}
+	} else {
+		name = callee.String()
+	}
+
+	v, err := callee.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.Type.IsFunc() {
+		return nil, utils.WithPos(fmt.Errorf("cannot call %s of type %s", name, v.Type.String()), f.Scope.Current().File, f.Pos)
+	}
+
+	return f.call(v.Value, v.Type, name, nil)
+}
+
+// call checks the arguments against the signature in typ, and calls callee.
+// Parameter names are only known for direct calls, otherwise parameters are referred to by their position.
+func (f *FnCallOp) call(callee value.Value, typ *Type, name string, names []string) (*Value, error) {
+	ft := typ.Func()
+
+	if len(f.Args) < len(ft.Params) || (!ft.Variadic && len(f.Args) > len(ft.Params)) {
+		expected := fmt.Sprintf("%d argument", len(ft.Params))
+		if len(ft.Params) != 1 {
+			expected += "s"
+		}
+
+		if ft.Variadic {
+			expected = "at least " + expected
+		}
+
+		return nil, utils.WithPos(fmt.Errorf("function '%s' expects %s, got %d", name, expected, len(f.Args)), f.Scope.Current().File, f.Pos)
 	}
 
 	values := []value.Value{}
-	// TODO: check if the number of arguments is correct
-	// TODO: check if the types of the arguments are correct
-	for _, arg := range f.Args {
-		v, err := arg.Value()
+	for i, arg := range f.Args {
+		if i >= len(ft.Params) {
+			v, err := f.variadic(arg)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, v.Value)
+			continue
+		}
+
+		param := ft.Params[i]
+
+		// untyped arguments adopt the type of the parameter
+		v, err := valueAs(arg, param)
+		if err != nil {
+			return nil, err
+		}
 
+		v, err = convert(f.Scope, v, param)
 		if err != nil {
 			return nil, err
 		}
 
+		if !v.Type.Equals(param) {
+			paramName := fmt.Sprintf("%d", i+1)
+			if names != nil {
+				paramName = "'" + names[i] + "'"
+			}
+
+			return nil, utils.WithPos(fmt.Errorf("cannot use %s as %s for parameter %s of function '%s'", v.Type.String(), param.String(), paramName, name), f.Scope.Current().File, arg.Position())
+		}
+
 		values = append(values, v.Value)
 	}
 
-	ret := f.Scope.BasicBlock().NewCall(fn.Ptr, values...)
+	ret := f.Scope.BasicBlock().NewCall(callee, values...)
 
 	return &Value{
-		Type:  fn.ReturnType,
+		Type:  ft.Return,
 		Value: ret,
 	}, nil
 }
 
+// variadic evaluates an argument passed in place of "...", with C's default argument promotions:
+// floats are passed as f64, and integers smaller than 32 bits are passed as i32
+func (f *FnCallOp) variadic(arg ExpressionLike) (*Value, error) {
+	v, err := arg.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Type.IsFloat() && v.Type.BasicSize() < 64 {
+		return cast(f.Scope, arg.Position(), v, NewTypeBasic(f.Scope, arg.Position(), BasicTypeF64))
+	}
+
+	if (v.Type.IsBool() || v.Type.IsInt() || v.Type.IsUInt()) && v.Type.BasicSize() < 32 {
+		return cast(f.Scope, arg.Position(), v, NewTypeBasic(f.Scope, arg.Position(), BasicTypeI32))
+	}
+
+	return v, nil
+}
+
 func (s *SizeOfOp) String() string {
 	return "sizeof(" + s.Expr.String() + ")"
 }
 
-func (s *SizeOfOp) Value() (*Value, error) {
-	bb := s.Scope.BasicBlock()
+func (s *SizeOfOp) Position() lexer.Position {
+	return s.Pos
+}
 
+func (s *SizeOfOp) Value() (*Value, error) {
 	var irType types.Type
 
 	if s.Type != nil {
@@ -628,12 +1339,13 @@ func (s *SizeOfOp) Value() (*Value, error) {
 	}
 
 	// source: https://stackoverflow.com/questions/14608250/how-can-i-find-the-size-of-a-type
-	size := bb.NewGetElementPtr(
+	// it's a constant expression, so it can be used to initialize globals
+	size := constant.NewGetElementPtr(
 		irType,
 		constant.NewNull(types.NewPointer(irType)),
 		NewLLInt(32, 1),
 	)
-	sizeInt := bb.NewPtrToInt(size, NewLLTypeInt(64))
+	sizeInt := constant.NewPtrToInt(size, NewLLTypeInt(64))
 
 	return &Value{
 		Type:  NewTypeBasic(s.Scope, s.Pos, BasicTypeI64),
@@ -645,9 +1357,25 @@ func (l *LoadOp) String() string {
 	return "load(" + l.Name + ")"
 }
 
+func (l *LoadOp) Position() lexer.Position {
+	return l.Pos
+}
+
 func (l *LoadOp) Value() (*Value, error) {
 	v := l.Scope.FindVariable(l.Name)
 	if v == nil {
+		// functions can be used as values, e.g. to pass callbacks
+		if fn := l.Scope.FindFunction(l.Name); fn != nil {
+			return &Value{
+				Type:  fn.Type(),
+				Value: fn.Ptr,
+			}, nil
+		}
+
+		if c := l.Scope.CurrentModule().FindConstant(l.Name); c != nil {
+			return c.Constant()
+		}
+
 		return nil, utils.WithPos(fmt.Errorf("variable %s not found", l.Name), l.Scope.Current().File, l.Pos)
 	}
 
@@ -656,7 +1384,14 @@ func (l *LoadOp) Value() (*Value, error) {
 		return nil, err
 	}
 
-	inst := l.Scope.BasicBlock().NewLoad(irType, v.Ptr)
+	// outside of functions the load is only there to take the address of a global, e.g. i64 *p = &x;
+	// so it isn't emitted, any use of the loaded value makes the initializer non-constant anyway
+	var inst *ir.InstLoad
+	if l.Scope.CurrentFunction() == nil {
+		inst = ir.NewLoad(irType, v.Ptr)
+	} else {
+		inst = l.Scope.BasicBlock().NewLoad(irType, v.Ptr)
+	}
 
 	return &Value{
 		Type:  v.Type,
@@ -669,6 +1404,10 @@ func (c *ConstantBoolOp) String() string {
 	return fmt.Sprintf("%s", c.Constant)
 }
 
+func (c *ConstantBoolOp) Position() lexer.Position {
+	return c.Pos
+}
+
 func (c *ConstantBoolOp) Value() (*Value, error) {
 	value := false
 
@@ -682,44 +1421,160 @@ func (c *ConstantBoolOp) Value() (*Value, error) {
 	}, nil
 }
 
-func (c *ConstantNumberOp) String() string {
-	return fmt.Sprintf("%s%s", c.Sign, c.Constant)
+func (c *ConstantNullOp) String() string {
+	return "null"
 }
 
-func (c *ConstantNumberOp) Value() (*Value, error) {
-	negative := false
-	if c.Sign == "-" {
-		negative = true
-	}
+func (c *ConstantNullOp) Position() lexer.Position {
+	return c.Pos
+}
 
-	// try to parse it as an integer first
-	i, err := strconv.ParseInt(c.Constant, 10, 64)
+func (c *ConstantNullOp) IsUntyped() bool {
+	return true
+}
+
+// Value returns null as void*, when there is no pointer type to adopt
+func (c *ConstantNullOp) Value() (*Value, error) {
+	return c.ValueAs(NewTypeBasic(c.Scope, c.Pos, BasicTypeVoid).NewPointer())
+}
 
+// ValueAs returns null as any pointer type, including function pointers and aliases of pointers
+func (c *ConstantNullOp) ValueAs(typ *Type) (*Value, error) {
+	if typ == nil || !(typ.IsPointer() || typ.IsFunc()) {
+		return c.Value()
+	}
+
+	irType, err := typ.IRType()
 	if err != nil {
-		// if failed, try to parse it as a float
-		f, err := strconv.ParseFloat(c.Constant, 64)
+		return nil, err
+	}
 
-		if err != nil {
-			return nil, utils.WithPos(fmt.Errorf("can't parse number"), c.Scope.Current().File, c.Pos)
-		}
+	return &Value{
+		Type:  typ,
+		Value: constant.NewNull(irType.(*types.PointerType)),
+	}, nil
+}
+
+func (c *ConstantNumberOp) String() string {
+	return fmt.Sprintf("%s%s", c.Sign, c.Constant)
+}
+
+func (c *ConstantNumberOp) Position() lexer.Position {
+	return c.Pos
+}
 
+func (c *ConstantNumberOp) IsUntyped() bool {
+	return true
+}
+
+// parse returns the number either as an integer or, if it's not an integer, as a float
+func (c *ConstantNumberOp) parse() (*big.Int, float64, error) {
+	negative := c.Sign == "-"
+
+	// try to parse it as an integer first
+	if i, ok := new(big.Int).SetString(c.Constant, 10); ok {
 		if negative {
-			f = -f
+			i.Neg(i)
 		}
 
-		return &Value{
-			Type:  NewTypeBasic(c.Scope, c.Pos, BasicTypeF64),
-			Value: NewLLFloat(64, f),
-		}, nil
+		return i, 0, nil
+	}
+
+	// if failed, try to parse it as a float
+	f, err := strconv.ParseFloat(c.Constant, 64)
+	if err != nil {
+		return nil, 0, utils.WithPos(fmt.Errorf("can't parse number"), c.Scope.Current().File, c.Pos)
 	}
 
 	if negative {
-		i = -i
+		f = -f
+	}
+
+	return nil, f, nil
+}
+
+func (c *ConstantNumberOp) Value() (*Value, error) {
+	return c.ValueAs(nil)
+}
+
+// ValueAs returns the number as typ, the kind of an alias decides, e.g. type i32 myint; myint x = 5;
+func (c *ConstantNumberOp) ValueAs(typ *Type) (*Value, error) {
+	i, f, err := c.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.as(typ, i, f)
+}
+
+// as returns the parsed number as typ, or as its default type if typ is nil or doesn't fit the kind of the number
+func (c *ConstantNumberOp) as(typ *Type, i *big.Int, f float64) (*Value, error) {
+	if typ != nil && i != nil && (typ.IsInt() || typ.IsUInt()) {
+		return c.integer(typ, i)
+	}
+
+	if typ != nil && i != nil && typ.IsFloat() {
+		f, _ = new(big.Float).SetInt(i).Float64()
+		return c.float(typ, f)
+	}
+
+	// floats are never converted to integers implicitly
+	if typ != nil && i == nil && typ.IsFloat() {
+		return c.float(typ, f)
+	}
+
+	// without a context, integers are i64 and floats are f64
+	if i != nil {
+		return c.integer(NewTypeBasic(c.Scope, c.Pos, BasicTypeI64), i)
+	}
+
+	return c.float(NewTypeBasic(c.Scope, c.Pos, BasicTypeF64), f)
+}
+
+func (c *ConstantNumberOp) integer(typ *Type, i *big.Int) (*Value, error) {
+	bits := uint(typ.LLVMIntType().BitSize)
+
+	var min, max *big.Int
+	if typ.IsInt() {
+		min = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), bits-1))
+		max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits-1), big.NewInt(1))
+	} else {
+		min = big.NewInt(0)
+		max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits), big.NewInt(1))
+	}
+
+	if i.Cmp(min) < 0 || i.Cmp(max) > 0 {
+		return nil, utils.WithPos(fmt.Errorf("constant %s overflows %s", c.String(), typ.String()), c.Scope.Current().File, c.Pos)
+	}
+
+	// llvm integers don't have a sign, so large unsigned values are stored in two's complement
+	x := new(big.Int).Set(i)
+	if typ.IsUInt() && x.Cmp(new(big.Int).Lsh(big.NewInt(1), bits-1)) >= 0 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), bits))
+	}
+
+	result := constant.NewInt(typ.LLVMIntType(), 0)
+	result.X = x
+
+	return &Value{
+		Type:  typ,
+		Value: result,
+	}, nil
+}
+
+func (c *ConstantNumberOp) float(typ *Type, f float64) (*Value, error) {
+	if typ.Basic() == BasicTypeF32 {
+		if math.Abs(f) > math.MaxFloat32 {
+			return nil, utils.WithPos(fmt.Errorf("constant %s overflows %s", c.String(), typ.String()), c.Scope.Current().File, c.Pos)
+		}
+
+		// the constant must be exactly representable as a 32 bit float
+		f = float64(float32(f))
 	}
 
 	return &Value{
-		Type:  NewTypeBasic(c.Scope, c.Pos, BasicTypeI64),
-		Value: NewLLInt(64, int(i)),
+		Type:  typ,
+		Value: constant.NewFloat(typ.LLVMFloatType(), f),
 	}, nil
 }
 
@@ -727,6 +1582,10 @@ func (c *ConstantCharOp) String() string {
 	return `'` + c.Constant + `'`
 }
 
+func (c *ConstantCharOp) Position() lexer.Position {
+	return c.Pos
+}
+
 func (c *ConstantCharOp) Value() (*Value, error) {
 	return &Value{
 		Type:  NewTypeBasic(c.Scope, c.Pos, BasicTypeI8),
@@ -738,13 +1597,18 @@ func (c *ConstantStringOp) String() string {
 	return `"` + c.Constant + `"`
 }
 
+func (c *ConstantStringOp) Position() lexer.Position {
+	return c.Pos
+}
+
 func (c *ConstantStringOp) Value() (*Value, error) {
 	m := c.Scope.CurrentModule()
 	id := m.GenerateID("id")
 	ptr := m.Ptr.NewGlobalDef(id, constant.NewCharArrayFromString(c.Constant+"\x00"))
 
+	// the cast is a constant expression, so strings can be used to initialize globals too
 	return &Value{
 		Type:  NewTypeBasic(c.Scope, c.Pos, BasicTypeI8).NewPointer(),
-		Value: c.Scope.BasicBlock().NewBitCast(ptr, types.NewPointer(NewLLTypeInt(8))),
+		Value: constant.NewBitCast(ptr, types.NewPointer(NewLLTypeInt(8))),
 	}, nil
 }
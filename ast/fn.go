@@ -3,7 +3,11 @@ package ast
 import (
 	"fmt"
 
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/klvnptr/k/utils"
 	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
 )
 
 func (f *Function) String() []string {
@@ -109,9 +113,48 @@ func (f *Function) SetBasicBlock(block *ir.Block) {
 	f.CurrentModule().SetBasicBlock(block)
 }
 
-func (f *Function) Generate() error {
+func (f *Function) CurrentLoop() *Loop {
+	return f.Loop
+}
+
+func (f *Function) SetCurrentLoop(l *Loop) {
+	f.Loop = l
+}
+
+// Type is the type of the function used as a value, e.g. cmp in qsort(p, n, size, cmp)
+func (f *Function) Type() *Type {
+	params := []*Type{}
+	for _, p := range f.Params {
+		params = append(params, p.Type)
+	}
+
+	return NewTypeFunc(f, f.Pos, params, f.Variadic, f.ReturnType)
+}
+
+// Declare creates the IR function, so it can be called before its body is generated.
+// Prototypes and the definition of the same function share the IR function of the first declaration.
+func (f *Function) Declare() error {
 	m := f.CurrentModule()
 
+	first := m.FindFunction(f.Name)
+	if first != f {
+		if !f.sameSignature(first) {
+			return utils.WithPos(fmt.Errorf("conflicting types for function '%s'", f.Name), f.Current().File, f.Pos)
+		}
+
+		if !f.OnlyDeclare {
+			for _, fn := range m.Functions {
+				if fn.Name == f.Name && fn != f && !fn.OnlyDeclare && fn.Ptr != nil {
+					return utils.WithPos(fmt.Errorf("function '%s' already has a body", f.Name), f.Current().File, f.Pos)
+				}
+			}
+		}
+
+		f.Ptr = first.Ptr
+
+		return nil
+	}
+
 	params := []*ir.Param{}
 
 	for _, p := range f.Params {
@@ -134,6 +177,24 @@ func (f *Function) Generate() error {
 		f.Ptr.Sig.Variadic = true
 	}
 
+	return nil
+}
+
+func (f *Function) sameSignature(o *Function) bool {
+	if f.Variadic != o.Variadic || len(f.Params) != len(o.Params) || !f.ReturnType.Equals(o.ReturnType) {
+		return false
+	}
+
+	for i, p := range f.Params {
+		if !p.Type.Equals(o.Params[i].Type) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *Function) Generate() error {
 	if f.OnlyDeclare {
 		return nil
 	}
@@ -149,36 +210,138 @@ func (f *Function) Generate() error {
 			return err
 		}
 
-		ptr := f.BasicBlock().NewAlloca(typ)
+		ptr := f.alloca(typ)
 		f.BasicBlock().NewStore(f.Ptr.Params[i], ptr)
 		p.Ptr = ptr
 	}
 
-	for _, stmt := range f.Body {
-		if err := stmt.Generate(); err != nil {
-			return err
+	f.DeadBlocks = map[*ir.Block]bool{}
+	f.Labels = nil
+
+	// return statements check their types themselves
+	if err := generateStatements(f, f.Body); err != nil {
+		return err
+	}
+
+	// gotos can jump forward, so labels are checked only after the whole body is generated
+	for _, l := range f.Labels {
+		if !l.Defined {
+			return utils.WithPos(fmt.Errorf("label '%s' is not defined", l.Name), f.Current().File, l.Pos)
 		}
 	}
 
-	returnIRType, err := f.ReturnType.IRType()
-	if err != nil {
-		return err
+	return f.terminate()
+}
+
+// alloca reserves stack space in the entry block, so it dominates every use even if a goto jumps over
+// the declaration, and loops don't allocate again in every iteration
+func (f *Function) alloca(typ types.Type) *ir.InstAlloca {
+	return f.Ptr.Blocks[0].NewAlloca(typ)
+}
+
+// label returns the label called name, and creates its block if it's not referred to yet
+func (f *Function) label(name string, pos lexer.Position) *Label {
+	for _, l := range f.Labels {
+		if l.Name == name {
+			return l
+		}
+	}
+
+	l := &Label{
+		Name:  name,
+		Block: f.Ptr.NewBlock(f.CurrentModule().GenerateID("label." + name)),
+		Pos:   pos,
+	}
+
+	f.Labels = append(f.Labels, l)
+
+	return l
+}
+
+// isDead reports whether nothing can be executed in bb anymore: either it's terminated already,
+// or no other block jumps to it. Blocks jumped to only from dead blocks are not detected.
+func (f *Function) isDead(bb *ir.Block) bool {
+	if bb.Term != nil {
+		return true
+	}
+
+	// the entry block is the only one that doesn't need a predecessor
+	if bb == f.Ptr.Blocks[0] {
+		return false
+	}
+
+	// a label can be jumped to by a goto later in the body, which isn't generated yet
+	for _, l := range f.Labels {
+		if l.Block == bb {
+			return false
+		}
 	}
 
-	if ret, ok := f.BasicBlock().Term.(*ir.TermRet); ok {
-		// X is nil, if return expression is void (NewRet was call with nil)
-		if ret.X != nil {
-			if f.ReturnType.IsVoid() {
-				return fmt.Errorf("function '%s' must not return a value", f.Name)
+	for _, b := range f.Ptr.Blocks {
+		if b.Term == nil {
+			continue
+		}
+
+		for _, succ := range b.Term.Succs() {
+			if succ == bb {
+				return false
 			}
+		}
+	}
 
-			if !ret.X.Type().Equal(returnIRType) {
-				return fmt.Errorf("function '%s' must return a value of type '%s'", f.Name, f.ReturnType.String())
+	return true
+}
+
+// successors returns the blocks term can jump to. A branch on a constant condition can only take one of them,
+// e.g. the loop of while (true) is left only by break or return.
+func successors(term ir.Terminator) []*ir.Block {
+	if br, ok := term.(*ir.TermCondBr); ok {
+		if cond, ok := br.Cond.(*constant.Int); ok {
+			// the successors of a conditional branch are the true target, then the false one
+			succs := br.Succs()
+			if cond.X.Sign() != 0 {
+				return succs[:1]
 			}
+
+			return succs[1:]
+		}
+	}
+
+	return term.Succs()
+}
+
+// terminate adds the missing terminators after the body is generated. Blocks reachable from the entry
+// block can only end without a return in void functions. The others are never executed.
+func (f *Function) terminate() error {
+	reachable := map[*ir.Block]bool{}
+	queue := []*ir.Block{f.Ptr.Blocks[0]}
+
+	for len(queue) > 0 {
+		bb := queue[0]
+		queue = queue[1:]
+
+		if reachable[bb] {
+			continue
+		}
+
+		reachable[bb] = true
+
+		if bb.Term != nil {
+			queue = append(queue, successors(bb.Term)...)
+		}
+	}
+
+	for _, bb := range f.Ptr.Blocks {
+		if bb.Term != nil {
+			continue
+		}
+
+		if !reachable[bb] {
+			bb.NewUnreachable()
+		} else if f.ReturnType.IsVoid() {
+			bb.NewRet(nil)
 		} else {
-			if !f.ReturnType.IsVoid() {
-				return fmt.Errorf("function '%s' must return a value of type '%s'", f.Name, f.ReturnType.String())
-			}
+			return utils.WithPos(fmt.Errorf("missing return in function '%s'", f.Name), f.Current().File, f.Pos)
 		}
 	}
 
@@ -0,0 +1,70 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/klvnptr/k/utils"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+)
+
+func (g *Global) String() []string {
+	if g.Expr != nil {
+		return []string{"global " + g.Variable.String() + " = " + g.Expr.String() + ";"}
+	}
+
+	return []string{"global " + g.Variable.String() + ";"}
+}
+
+func (g *Global) Generate() error {
+	m := g.Scope.CurrentModule()
+
+	if m.FindVariable(g.Variable.Ident) != g.Variable {
+		return utils.WithPos(fmt.Errorf("global variable '%s' already exists", g.Variable.Ident), g.Scope.Current().File, g.Pos)
+	}
+
+	if m.FindFunction(g.Variable.Ident) != nil {
+		return utils.WithPos(fmt.Errorf("global variable '%s' is already declared as a function", g.Variable.Ident), g.Scope.Current().File, g.Pos)
+	}
+
+	typ, err := g.Variable.Type.IRType()
+	if err != nil {
+		return err
+	}
+
+	// globals without an initializer are zero-initialized, like in C
+	var init constant.Constant = constant.NewZeroInitializer(typ)
+
+	if g.Expr != nil {
+		// there is no function to put instructions into, so expressions get a throwaway block, and anything
+		// emitted into it would be lost, e.g. the store of i64 b = a = 5;
+		bb := ir.NewBlock("")
+		g.Scope.SetBasicBlock(bb)
+		defer g.Scope.SetBasicBlock(nil)
+
+		expr, err := valueAs(g.Expr, g.Variable.Type)
+		if err != nil {
+			return err
+		}
+
+		expr, err = convert(g.Scope, expr, g.Variable.Type)
+		if err != nil {
+			return err
+		}
+
+		if !expr.Type.Equals(g.Variable.Type) {
+			return utils.WithPos(fmt.Errorf("cannot assign %s to %s", expr.Type.String(), g.Variable.Type.String()), g.Scope.Current().File, g.Pos)
+		}
+
+		c, ok := expr.Value.(constant.Constant)
+		if !ok || len(bb.Insts) > 0 || bb.Term != nil {
+			return utils.WithPos(fmt.Errorf("initializer of global variable '%s' must be a constant", g.Variable.Ident), g.Scope.Current().File, g.Pos)
+		}
+
+		init = c
+	}
+
+	g.Variable.Ptr = m.Ptr.NewGlobalDef(g.Variable.Ident, init)
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/klvnptr/k/utils"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+// ValueAs returns the string as an i8 array if typ is one, otherwise as a pointer to a global constant
+func (c *ConstantStringOp) ValueAs(typ *Type) (*Value, error) {
+	if !typ.IsArray() || !typ.Array().Type.IsBasic() || typ.Array().Type.Basic() != BasicTypeI8 {
+		return c.Value()
+	}
+
+	length := typ.Array().Len
+
+	// like in C, the terminating zero is only added if there is room for it
+	if len(c.Constant) > length {
+		return nil, utils.WithPos(fmt.Errorf("string of length %d is too long for array of length %d", len(c.Constant), length), c.Scope.Current().File, c.Pos)
+	}
+
+	chars := make([]byte, length)
+	copy(chars, c.Constant)
+
+	return &Value{
+		Type:  typ,
+		Value: constant.NewCharArray(chars),
+	}, nil
+}
+
+func (c *ConstantStringOp) IsUntyped() bool {
+	return false
+}
+
+func (il *InitializerListOp) String() string {
+	elements := []string{}
+	for _, el := range il.Elements {
+		if el.Index != nil {
+			elements = append(elements, fmt.Sprintf("[%s] = %s", el.Index.String(), el.Expr.String()))
+		} else {
+			elements = append(elements, el.Expr.String())
+		}
+	}
+
+	return fmt.Sprintf("{ %s, }", strings.Join(elements, ", "))
+}
+
+func (il *InitializerListOp) Position() lexer.Position {
+	return il.Pos
+}
+
+func (il *InitializerListOp) Value() (*Value, error) {
+	return nil, utils.WithPos(fmt.Errorf("initializer list can only be used to initialize a variable"), il.Scope.Current().File, il.Pos)
+}
+
+func (il *InitializerListOp) IsUntyped() bool {
+	return true
+}
+
+// ValueAs generates the initializer list as a value of typ
+func (il *InitializerListOp) ValueAs(typ *Type) (*Value, error) {
+	// also makes sure that aliases exist
+	irType, err := typ.IRType()
+	if err != nil {
+		return nil, err
+	}
+
+	var elementTypes []*Type
+
+	if typ.IsArray() {
+		for i := 0; i < typ.Array().Len; i++ {
+			elementTypes = append(elementTypes, typ.Array().Type)
+		}
+	} else if typ.IsStruct() && !typ.Struct().Union {
+		for _, f := range typ.Struct().Fields {
+			elementTypes = append(elementTypes, f.Type)
+		}
+	} else {
+		return nil, utils.WithPos(fmt.Errorf("cannot use initializer list for %s", typ.String()), il.Scope.Current().File, il.Pos)
+	}
+
+	values := make([]*Value, len(elementTypes))
+	next := 0
+
+	for _, el := range il.Elements {
+		if el.Index != nil {
+			if !typ.IsArray() {
+				return nil, utils.WithPos(fmt.Errorf("designated index can only be used for arrays"), il.Scope.Current().File, el.Pos)
+			}
+
+			index, err := el.Index.Value()
+			if err != nil {
+				return nil, err
+			}
+
+			c, ok := index.Value.(*constant.Int)
+			if !ok || !(index.Type.IsInt() || index.Type.IsUInt()) {
+				return nil, utils.WithPos(fmt.Errorf("array index in initializer must be an integer constant"), il.Scope.Current().File, el.Pos)
+			}
+
+			next = int(c.X.Int64())
+
+			if next < 0 || next >= len(values) {
+				return nil, utils.WithPos(fmt.Errorf("index %d in initializer is out of bounds for array of length %d", next, len(values)), il.Scope.Current().File, el.Pos)
+			}
+		}
+
+		if next >= len(values) {
+			if typ.IsArray() {
+				return nil, utils.WithPos(fmt.Errorf("too many elements in initializer for array of length %d", typ.Array().Len), il.Scope.Current().File, el.Pos)
+			}
+
+			return nil, utils.WithPos(fmt.Errorf("too many elements in initializer for struct with %d fields", len(values)), il.Scope.Current().File, el.Pos)
+		}
+
+		v, err := valueAs(el.Expr, elementTypes[next])
+		if err != nil {
+			return nil, err
+		}
+
+		v, err = convert(il.Scope, v, elementTypes[next])
+		if err != nil {
+			return nil, err
+		}
+
+		if !v.Type.Equals(elementTypes[next]) {
+			return nil, utils.WithPos(fmt.Errorf("cannot assign %s to %s", v.Type.String(), elementTypes[next].String()), il.Scope.Current().File, el.Pos)
+		}
+
+		// like in C, a designated element can override a previous one
+		values[next] = v
+		next++
+	}
+
+	allConstant := true
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+
+		if _, ok := v.Value.(constant.Constant); !ok {
+			allConstant = false
+		}
+	}
+
+	// omitted elements are zero
+	if allConstant {
+		elements := make([]constant.Constant, len(values))
+
+		for i, v := range values {
+			if v == nil {
+				elementIRType, err := elementTypes[i].IRType()
+				if err != nil {
+					return nil, err
+				}
+
+				elements[i] = constant.NewZeroInitializer(elementIRType)
+			} else {
+				elements[i] = v.Value.(constant.Constant)
+			}
+		}
+
+		var result constant.Constant
+		if typ.IsArray() {
+			result = constant.NewArray(irType.(*types.ArrayType), elements...)
+		} else {
+			result = constant.NewStruct(irType.(*types.StructType), elements...)
+		}
+
+		return &Value{
+			Type:  typ,
+			Value: result,
+		}, nil
+	}
+
+	// otherwise we store the elements one by one into a zeroed temporary
+	bb := il.Scope.BasicBlock()
+
+	// outside of functions the throwaway block of global initializers is used, see Global.Generate
+	var tmp *ir.InstAlloca
+	if fn := il.Scope.CurrentFunction(); fn != nil {
+		tmp = fn.alloca(irType)
+	} else {
+		tmp = bb.NewAlloca(irType)
+	}
+
+	bb.NewStore(constant.NewZeroInitializer(irType), tmp)
+
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+
+		ptr := bb.NewGetElementPtr(irType, tmp, NewLLInt(32, 0), NewLLInt(32, i))
+		bb.NewStore(v.Value, ptr)
+	}
+
+	return &Value{
+		Type:  typ,
+		Value: bb.NewLoad(irType, tmp),
+	}, nil
+}
@@ -70,14 +70,14 @@ func (suite *KTestSuite) TestK00006() {
 }
 
 // file://./../testsuite/00007.k
-// func (suite *KTestSuite) TestK00007() {
-// 	suite.EqualTestCase(7)
-// }
+func (suite *KTestSuite) TestK00007() {
+	suite.EqualTestCase(7)
+}
 
 // file://./../testsuite/00008.k
-// func (suite *KTestSuite) TestK00008() {
-// 	suite.EqualTestCase(8)
-// }
+func (suite *KTestSuite) TestK00008() {
+	suite.EqualTestCase(8)
+}
 
 // file://./../testsuite/00009.k
 func (suite *KTestSuite) TestK00009() {
@@ -85,9 +85,9 @@ func (suite *KTestSuite) TestK00009() {
 }
 
 // file://./../testsuite/00010.k
-// func (suite *KTestSuite) TestK00010() {
-// 	suite.EqualTestCase(10)
-// }
+func (suite *KTestSuite) TestK00010() {
+	suite.EqualTestCase(10)
+}
 
 // file://./../testsuite/00011.k
 // func (suite *KTestSuite) TestK00011() {
@@ -149,17 +149,15 @@ func (suite *KTestSuite) TestK00022() {
 	suite.EqualTestCase(22)
 }
 
-// globals
 // file://./../testsuite/00023.k
-// func (suite *KTestSuite) TestK00023() {
-// 	suite.EqualTestCase(23)
-// }
+func (suite *KTestSuite) TestK00023() {
+	suite.EqualTestCase(23)
+}
 
-// globals
 // file://./../testsuite/00024.k
-// func (suite *KTestSuite) TestK00024() {
-// 	suite.EqualTestCase(24)
-// }
+func (suite *KTestSuite) TestK00024() {
+	suite.EqualTestCase(24)
+}
 
 // file://./../testsuite/00025.k
 func (suite *KTestSuite) TestK00025() {
@@ -201,34 +199,30 @@ func (suite *KTestSuite) TestK00032() {
 	suite.EqualTestCase(32)
 }
 
-// globals
 // file://./../testsuite/00033.k
-// func (suite *KTestSuite) TestK00033() {
-// 	suite.EqualTestCase(33)
-// }
+func (suite *KTestSuite) TestK00033() {
+	suite.EqualTestCase(33)
+}
 
-// for, continue, break
 // file://./../testsuite/00034.k
-// func (suite *KTestSuite) TestK00034() {
-// 	suite.EqualTestCase(34)
-// }
+func (suite *KTestSuite) TestK00034() {
+	suite.EqualTestCase(34)
+}
 
 // file://./../testsuite/00035.k
 func (suite *KTestSuite) TestK00035() {
 	suite.EqualTestCase(35)
 }
 
-// +=, -=, *=, /= operators
 // file://./../testsuite/00036.k
-// func (suite *KTestSuite) TestK00036() {
-// 	suite.EqualTestCase(36)
-// }
+func (suite *KTestSuite) TestK00036() {
+	suite.EqualTestCase(36)
+}
 
-// subtract a pointer from another pointer
 // file://./../testsuite/00037.k
-// func (suite *KTestSuite) TestK00037() {
-// 	suite.EqualTestCase(37)
-// }
+func (suite *KTestSuite) TestK00037() {
+	suite.EqualTestCase(37)
+}
 
 // sizeof operator
 // file://./../testsuite/00038.k
@@ -252,11 +246,10 @@ func (suite *KTestSuite) TestK00041() {
 	suite.EqualTestCase(41)
 }
 
-// union
 // file://./../testsuite/00042.k
-// func (suite *KTestSuite) TestK00042() {
-// 	suite.EqualTestCase(42)
-// }
+func (suite *KTestSuite) TestK00042() {
+	suite.EqualTestCase(42)
+}
 
 // file://./../testsuite/00043.k
 func (suite *KTestSuite) TestK00043() {
@@ -269,11 +262,10 @@ func (suite *KTestSuite) TestK00043() {
 // 	suite.EqualTestCase(44)
 // }
 
-// globals with init
 // file://./../testsuite/00045.k
-// func (suite *KTestSuite) TestK00045() {
-// 	suite.EqualTestCase(45)
-// }
+func (suite *KTestSuite) TestK00045() {
+	suite.EqualTestCase(45)
+}
 
 // union, unnamd struct & union
 // file://./../testsuite/00046.k
@@ -281,17 +273,15 @@ func (suite *KTestSuite) TestK00043() {
 // 	suite.EqualTestCase(46)
 // }
 
-// struct assign
 // file://./../testsuite/00047.k
-// func (suite *KTestSuite) TestK00047() {
-// 	suite.EqualTestCase(47)
-// }
+func (suite *KTestSuite) TestK00047() {
+	suite.EqualTestCase(47)
+}
 
-// struct assign
 // file://./../testsuite/00048.k
-// func (suite *KTestSuite) TestK00048() {
-// 	suite.EqualTestCase(48)
-// }
+func (suite *KTestSuite) TestK00048() {
+	suite.EqualTestCase(48)
+}
 
 // globals, struct assign
 // file://./../testsuite/00049.k
@@ -323,17 +313,15 @@ func (suite *KTestSuite) TestK00043() {
 // 	suite.EqualTestCase(53)
 // }
 
-// enum
 // file://./../testsuite/00054.k
-// func (suite *KTestSuite) TestK00054() {
-// 	suite.EqualTestCase(54)
-// }
+func (suite *KTestSuite) TestK00054() {
+	suite.EqualTestCase(54)
+}
 
-// enum
 // file://./../testsuite/00055.k
-// func (suite *KTestSuite) TestK00055() {
-// 	suite.EqualTestCase(55)
-// }
+func (suite *KTestSuite) TestK00055() {
+	suite.EqualTestCase(55)
+}
 
 // file://./../testsuite/00056.k
 func (suite *KTestSuite) TestK00056() {
@@ -451,11 +439,10 @@ func (suite *KTestSuite) TestK00073() {
 // 	suite.EqualTestCase(75)
 // }
 
-// inline if expression
 // file://./../testsuite/00076.k
-// func (suite *KTestSuite) TestK00076() {
-// 	suite.EqualTestCase(76)
-// }
+func (suite *KTestSuite) TestK00076() {
+	suite.EqualTestCase(76)
+}
 
 // file://./../testsuite/00077.k
 func (suite *KTestSuite) TestK00077() {
@@ -536,23 +523,20 @@ func (suite *KTestSuite) TestK00086() {
 // 	suite.EqualTestCase(90)
 // }
 
-// struct assign
 // file://./../testsuite/00091.k
-// func (suite *KTestSuite) TestK00091() {
-// 	suite.EqualTestCase(91)
-// }
+func (suite *KTestSuite) TestK00091() {
+	suite.EqualTestCase(91)
+}
 
-// struct assign
 // file://./../testsuite/00092.k
-// func (suite *KTestSuite) TestK00092() {
-// 	suite.EqualTestCase(92)
-// }
+func (suite *KTestSuite) TestK00092() {
+	suite.EqualTestCase(92)
+}
 
-// struct assign
 // file://./../testsuite/00093.k
-// func (suite *KTestSuite) TestK00093() {
-// 	suite.EqualTestCase(93)
-// }
+func (suite *KTestSuite) TestK00093() {
+	suite.EqualTestCase(93)
+}
 
 // extern, multi file
 // file://./../testsuite/00094.k
@@ -593,11 +577,10 @@ func (suite *KTestSuite) TestK00100() {
 	suite.EqualTestCase(100)
 }
 
-// do-while
 // file://./../testsuite/00101.k
-// func (suite *KTestSuite) TestK00101() {
-// 	suite.EqualTestCase(101)
-// }
+func (suite *KTestSuite) TestK00101() {
+	suite.EqualTestCase(101)
+}
 
 // file://./../testsuite/00102.k
 // func (suite *KTestSuite) TestK00102() {
@@ -635,9 +618,9 @@ func (suite *KTestSuite) TestK00100() {
 // }
 
 // file://./../testsuite/00109.k
-// func (suite *KTestSuite) TestK00109() {
-// 	suite.EqualTestCase(109)
-// }
+func (suite *KTestSuite) TestK00109() {
+	suite.EqualTestCase(109)
+}
 
 // file://./../testsuite/00110.k
 // func (suite *KTestSuite) TestK00110() {
@@ -880,9 +863,9 @@ func (suite *KTestSuite) TestK00100() {
 // }
 
 // file://./../testsuite/00158.k
-// func (suite *KTestSuite) TestK00158() {
-// 	suite.EqualTestCase(158)
-// }
+func (suite *KTestSuite) TestK00158() {
+	suite.EqualTestCase(158)
+}
 
 // file://./../testsuite/00159.k
 // func (suite *KTestSuite) TestK00159() {
@@ -1055,9 +1038,9 @@ func (suite *KTestSuite) TestK00179() {
 // }
 
 // file://./../testsuite/00193.k
-// func (suite *KTestSuite) TestK00193() {
-// 	suite.EqualTestCase(193)
-// }
+func (suite *KTestSuite) TestK00193() {
+	suite.EqualTestCase(193)
+}
 
 // file://./../testsuite/00194.k
 // func (suite *KTestSuite) TestK00194() {
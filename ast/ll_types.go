@@ -1,8 +1,12 @@
 package ast
 
 import (
+	"math/big"
+
+	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/constant"
 	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
 )
 
 func NewLLTypeVoid() *types.VoidType {
@@ -48,6 +52,202 @@ func NewLLInt(bitSize uint64, value int) *constant.Int {
 	return constant.NewInt(NewLLTypeInt(bitSize), int64(value))
 }
 
+// convertLLInt converts the constant c to typ exactly, extending by the signedness of the source like a cast does.
+// Like all constants, values with the top bit set are stored as negative numbers.
+func convertLLInt(c *constant.Int, signed bool, typ *types.IntType) *constant.Int {
+	srcBits, dstBits := uint(c.Typ.BitSize), uint(typ.BitSize)
+
+	// the value of c as an unsigned number
+	x := new(big.Int).Mod(c.X, new(big.Int).Lsh(big.NewInt(1), srcBits))
+	if signed && x.Bit(int(srcBits)-1) == 1 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), srcBits))
+	}
+
+	x.Mod(x, new(big.Int).Lsh(big.NewInt(1), dstBits))
+	if x.Bit(int(dstBits)-1) == 1 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), dstBits))
+	}
+
+	result := constant.NewInt(typ, 0)
+	result.X = x
+
+	return result
+}
+
 func NewLLFloat(bitSize uint64, value float64) *constant.Float {
 	return constant.NewFloat(NewLLTypeFloat(bitSize), value)
 }
+
+// LLLayout returns the size and alignment of typ in bytes, as laid out on 64 bit targets
+func LLLayout(typ types.Type) (uint64, uint64) {
+	switch t := typ.(type) {
+	case *types.IntType:
+		size := uint64(1)
+		for size*8 < t.BitSize {
+			size *= 2
+		}
+
+		return size, size
+	case *types.FloatType:
+		if t.Kind == types.FloatKindFloat {
+			return 4, 4
+		}
+
+		return 8, 8
+	case *types.PointerType:
+		return 8, 8
+	case *types.ArrayType:
+		size, align := LLLayout(t.ElemType)
+
+		return size * t.Len, align
+	case *types.StructType:
+		var size, align uint64 = 0, 1
+
+		for _, f := range t.Fields {
+			fs, fa := LLLayout(f)
+
+			size = alignTo(size, fa) + fs
+			if fa > align {
+				align = fa
+			}
+		}
+
+		return alignTo(size, align), align
+	default:
+		panic("unknown layout of type " + typ.LLString())
+	}
+}
+
+func alignTo(size, align uint64) uint64 {
+	return (size + align - 1) / align * align
+}
+
+// foldLL replaces v with the equivalent constant expression in the initializers of globals, if v is the last instruction
+// of the current block and all of its operands are constants, e.g. i32 g = (i32)5;
+// Inside functions instructions are kept, because llvm doesn't support every constant expression.
+func foldLL(scope ScopeLike, v value.Value) value.Value {
+	bb := scope.BasicBlock()
+
+	inst, ok := v.(ir.Instruction)
+	if !ok || scope.CurrentFunction() != nil || bb == nil || len(bb.Insts) == 0 || bb.Insts[len(bb.Insts)-1] != inst {
+		return v
+	}
+
+	c := foldLLInst(inst)
+	if c == nil {
+		return v
+	}
+
+	bb.Insts = bb.Insts[:len(bb.Insts)-1]
+
+	return c
+}
+
+func foldLLInst(inst ir.Instruction) constant.Constant {
+	operands := func(vs ...value.Value) ([]constant.Constant, bool) {
+		cs := []constant.Constant{}
+		for _, v := range vs {
+			c, ok := v.(constant.Constant)
+			if !ok {
+				return nil, false
+			}
+
+			cs = append(cs, c)
+		}
+
+		return cs, true
+	}
+
+	binary := func(x, y value.Value, expr func(x, y constant.Constant) constant.Constant) constant.Constant {
+		if cs, ok := operands(x, y); ok {
+			return expr(cs[0], cs[1])
+		}
+
+		return nil
+	}
+
+	conversion := func(from value.Value, to types.Type, expr func(from constant.Constant, to types.Type) constant.Constant) constant.Constant {
+		if cs, ok := operands(from); ok {
+			return expr(cs[0], to)
+		}
+
+		return nil
+	}
+
+	switch i := inst.(type) {
+	case *ir.InstAdd:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewAdd(x, y) })
+	case *ir.InstSub:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewSub(x, y) })
+	case *ir.InstMul:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewMul(x, y) })
+	case *ir.InstSDiv:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewSDiv(x, y) })
+	case *ir.InstUDiv:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewUDiv(x, y) })
+	case *ir.InstSRem:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewSRem(x, y) })
+	case *ir.InstURem:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewURem(x, y) })
+	case *ir.InstShl:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewShl(x, y) })
+	case *ir.InstLShr:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewLShr(x, y) })
+	case *ir.InstAShr:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewAShr(x, y) })
+	case *ir.InstAnd:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewAnd(x, y) })
+	case *ir.InstOr:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewOr(x, y) })
+	case *ir.InstXor:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewXor(x, y) })
+	case *ir.InstFAdd:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewFAdd(x, y) })
+	case *ir.InstFSub:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewFSub(x, y) })
+	case *ir.InstFMul:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewFMul(x, y) })
+	case *ir.InstFDiv:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewFDiv(x, y) })
+	case *ir.InstFRem:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewFRem(x, y) })
+	case *ir.InstICmp:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewICmp(i.Pred, x, y) })
+	case *ir.InstFCmp:
+		return binary(i.X, i.Y, func(x, y constant.Constant) constant.Constant { return constant.NewFCmp(i.Pred, x, y) })
+	case *ir.InstFNeg:
+		if cs, ok := operands(i.X); ok {
+			return constant.NewFNeg(cs[0])
+		}
+	case *ir.InstTrunc:
+		return conversion(i.From, i.To, func(c constant.Constant, t types.Type) constant.Constant { return constant.NewTrunc(c, t) })
+	case *ir.InstZExt:
+		return conversion(i.From, i.To, func(c constant.Constant, t types.Type) constant.Constant { return constant.NewZExt(c, t) })
+	case *ir.InstSExt:
+		return conversion(i.From, i.To, func(c constant.Constant, t types.Type) constant.Constant { return constant.NewSExt(c, t) })
+	case *ir.InstFPTrunc:
+		return conversion(i.From, i.To, func(c constant.Constant, t types.Type) constant.Constant { return constant.NewFPTrunc(c, t) })
+	case *ir.InstFPExt:
+		return conversion(i.From, i.To, func(c constant.Constant, t types.Type) constant.Constant { return constant.NewFPExt(c, t) })
+	case *ir.InstFPToSI:
+		return conversion(i.From, i.To, func(c constant.Constant, t types.Type) constant.Constant { return constant.NewFPToSI(c, t) })
+	case *ir.InstFPToUI:
+		return conversion(i.From, i.To, func(c constant.Constant, t types.Type) constant.Constant { return constant.NewFPToUI(c, t) })
+	case *ir.InstSIToFP:
+		return conversion(i.From, i.To, func(c constant.Constant, t types.Type) constant.Constant { return constant.NewSIToFP(c, t) })
+	case *ir.InstUIToFP:
+		return conversion(i.From, i.To, func(c constant.Constant, t types.Type) constant.Constant { return constant.NewUIToFP(c, t) })
+	case *ir.InstPtrToInt:
+		return conversion(i.From, i.To, func(c constant.Constant, t types.Type) constant.Constant { return constant.NewPtrToInt(c, t) })
+	case *ir.InstIntToPtr:
+		return conversion(i.From, i.To, func(c constant.Constant, t types.Type) constant.Constant { return constant.NewIntToPtr(c, t) })
+	case *ir.InstBitCast:
+		return conversion(i.From, i.To, func(c constant.Constant, t types.Type) constant.Constant { return constant.NewBitCast(c, t) })
+	case *ir.InstGetElementPtr:
+		if cs, ok := operands(append([]value.Value{i.Src}, i.Indices...)...); ok {
+			return constant.NewGetElementPtr(i.ElemType, cs[0], cs[1:]...)
+		}
+	}
+
+	return nil
+}
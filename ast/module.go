@@ -14,6 +14,10 @@ func (m *Module) String() []string {
 		lines = append(lines, td.String()...)
 	}
 
+	for _, g := range m.Globals {
+		lines = append(lines, g.String()...)
+	}
+
 	for _, fn := range m.Functions {
 		lines = append(lines, fn.String()...)
 	}
@@ -94,6 +98,40 @@ func (m *Module) FindFunction(ident string) *Function {
 	return nil
 }
 
+// FindConstant looks up an enum member, they share the namespace of globals and functions
+func (m *Module) FindConstant(ident string) *EnumConstant {
+	for _, td := range m.LocalTypes {
+		for _, c := range td.Constants {
+			if c.Ident == ident {
+				return c
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkConstants reports enum members which clash with other names or don't fit the enum type
+func (m *Module) checkConstants() error {
+	seen := map[string]bool{}
+
+	for _, td := range m.LocalTypes {
+		for _, c := range td.Constants {
+			if seen[c.Ident] || m.FindVariable(c.Ident) != nil || m.FindFunction(c.Ident) != nil {
+				return utils.WithPos(fmt.Errorf("enum constant '%s' already exists", c.Ident), m.Current().File, c.Pos)
+			}
+
+			seen[c.Ident] = true
+
+			if _, err := c.Constant(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func (m *Module) CurrentModule() *Module {
 	return m
 }
@@ -110,11 +148,33 @@ func (m *Module) SetBasicBlock(b *ir.Block) {
 	m.CurrentBlock = b
 }
 
+func (m *Module) CurrentLoop() *Loop {
+	return nil
+}
+
+func (m *Module) SetCurrentLoop(l *Loop) {
+	panic("loops must be inside a function")
+}
+
 func (m *Module) Generate() (*ir.Module, error) {
 	m.Ptr = ir.NewModule()
 
-	if len(m.Globals) > 0 {
-		panic("not implemented")
+	if err := m.checkConstants(); err != nil {
+		return nil, err
+	}
+
+	// all functions are declared first, so they can be called regardless of the order they are defined in
+	for _, fn := range m.Functions {
+		if err := fn.Declare(); err != nil {
+			return nil, err
+		}
+	}
+
+	// globals must be generated before function bodies, functions refer to them
+	for _, g := range m.Globals {
+		if err := g.Generate(); err != nil {
+			return nil, err
+		}
 	}
 
 	for _, fn := range m.Functions {
@@ -149,6 +209,10 @@ func (m *Module) Generate() (*ir.Module, error) {
 	return m.Ptr, nil
 }
 
+func (m *Module) Warn(err error) {
+	m.Warnings = append(m.Warnings, err)
+}
+
 func (m *Module) GenerateID(prefix string) string {
 	id := fmt.Sprintf("%s.%d", prefix, m.LastID)
 
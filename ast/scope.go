@@ -27,6 +27,8 @@ type ScopeLike interface {
 	CurrentFunction() *Function
 	BasicBlock() *ir.Block
 	SetBasicBlock(b *ir.Block)
+	CurrentLoop() *Loop
+	SetCurrentLoop(l *Loop)
 }
 
 func NewScope(file *utils.File) *Scope {
@@ -155,7 +155,8 @@ func (suite *SrcTestSuite) TestEmptyString() {
 }
 
 func (suite *SrcTestSuite) TestAlias0a() {
-	suite.ErrorGenerateExprK(`hello a = (hello)10; bool d = a == 10;`, "incompatible types hello and i64", testing.Declare("type i64 hello;"))
+	suite.ErrorGenerateExprK(`hello a = (hello)10; bool d = a == (i64)10;`, "incompatible types hello and i64", testing.Declare("type i64 hello;"))
+	suite.EqualExprK(`hello a = 10; bool d = a == 10;`, `"%d", d`, "1", testing.Declare("type i64 hello;"))
 	suite.EqualExprK(`hello a = (hello)10; bool d = a == (hello)10;`, `"%d", d`, "1", testing.Declare("type i64 hello;"))
 	suite.EqualExprK(`i64 f = 10; hello g = (hello)10; bool h = f == (i64)g;`, `"%d", h`, "1", testing.Declare("type i64 hello;"), testing.Basename("first"))
 }
@@ -203,6 +204,157 @@ func (suite *SrcTestSuite) TestLoop() {
 	suite.EqualProgramK(src, "9\n8\n7\n6\n5\n4\n3\n2\n1\n0\n")
 }
 
+func (suite *SrcTestSuite) TestForLoop() {
+	src := `
+	i64 printf(i8 *fmt,... );
+
+	i64 main() {
+		i64 sum = 0;
+
+		for (i64 i = 0; i < 5; i++) {
+			sum = sum + i;
+		}
+
+		// i is scoped to the loop above, so we can declare it again
+		for (i64 i = 10; i > 8; i = i - 1)
+			printf("%d,", i);
+
+		printf("%d", sum);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "10,9,10")
+
+	suite.ErrorGenerateExprK(`for (i64 i = 0; i < 5; i++) {} i64 j = i;`, "variable i not found")
+}
+
+func (suite *SrcTestSuite) TestBreakContinue() {
+	src := `
+	i64 printf(i8 *fmt,... );
+
+	i64 main() {
+		i64 i = 0;
+
+		while (true) {
+			i++;
+
+			if (i % 2 == 0) {
+				continue;
+			}
+
+			if (i > 7) {
+				break;
+			}
+
+			for (i64 j = 0; j < 10; j++) {
+				if (j == 2)
+					break;
+
+				printf("%d.%d,", i, j);
+			}
+		}
+
+		printf("%d", i);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "1.0,1.1,3.0,3.1,5.0,5.1,7.0,7.1,9")
+
+	suite.ErrorGenerateExprK(`break;`, "break statement not within a loop or switch")
+	suite.ErrorGenerateExprK(`if (true) { continue; }`, "continue statement not within a loop")
+}
+
+func (suite *SrcTestSuite) TestCompoundAssign() {
+	suite.EqualExprK(`i64 x = 10; x += 5; x -= 3; x *= 4; x /= 6; x %= 5;`, `"%d", x`, "3")
+	suite.EqualExprK(`i64 x = 12; x &= 10; x |= 1; x ^= 16;`, `"%d", x`, "25")
+	suite.EqualExprK(`f64 x = 1.5; x *= 3.0;`, `"%.2f", x`, "4.50")
+	suite.EqualExprK(`i8 *s = "hello"; s += 2;`, `"%s", s`, "llo")
+
+	// the target is evaluated only once
+	suite.EqualExprK(`[3]i64 a; a[0] = 0; a[1] = 0; a[2] = 0; i64 i = 1; a[i++] += 5;`, `"%d,%d,%d,%d", a[0], a[1], a[2], i`, "0,5,0,2")
+	suite.EqualExprK(`i64 s = 0; for (i64 i = 1; i <= 4; i += 1) s += i;`, `"%d", s`, "10")
+
+	suite.ErrorGenerateExprK(`i64 x = 1; x += 1.0;`, "cannot assign f64 to i64")
+	suite.ErrorGenerateExprK(`2 += 1;`, "cannot assign to non-variable")
+}
+
+func (suite *SrcTestSuite) TestGlobals() {
+	src := `
+	type struct { i64 a, i64 b, } pair;
+
+	i64 printf(i8 *fmt,... );
+
+	i64 counter = 40;
+	i8 *greeting = "hello";
+	[3]i64 slots;
+	pair p;
+	f64 ratio;
+
+	i64 next() {
+		counter++;
+		return counter;
+	}
+
+	i64 main() {
+		next();
+		slots[1] = next();
+		p.b = 7;
+
+		printf("%s %d,%d,%d,%d,%.1f", greeting, counter, slots[0], slots[1], p.a + p.b, ratio);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "hello 42,0,42,7,0.0")
+
+	// casts, sizeof and unary operators of constants are constants
+	src = `
+	i64 printf(i8 *fmt,... );
+
+	i32 g = (i32)5;
+	i8* p = (i8*)0;
+	f64 d = (f64)1;
+	i64 n = sizeof(i64) * 2;
+	bool b = !false;
+	i64 m = -((i64)(u8)255);
+	u8 t = (u8)(i64)300;
+
+	i64 main() {
+		printf("%d,%d,%.1f,%d,%d,%d,%d", (i64)g, p == (i8*)0, d, n, b, m, (i64)t);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "5,1,1.0,16,1,-255,44")
+
+	// inside functions the same operations are regular instructions
+	suite.EqualExprK(`i64 n = sizeof(i64) / 2; f64 d = (f64)1 / (f64)4; u8 t = (u8)(i64)300;`, `"%d,%.2f,%d", n, d, (i64)t`, "4,0.25,44")
+
+	suite.ErrorGenerateProgramK(`
+	i64 a = 1;
+	i64 b = a + 1;
+
+	i64 main() {
+		return 0;
+	}
+	`, "initializer of global variable 'b' must be a constant")
+
+	suite.ErrorGenerateProgramK(`i64 a; i64 b = a = 5; i64 main() { return 0; }`, "initializer of global variable 'b' must be a constant")
+	suite.ErrorGenerateProgramK(`i64 a; i64 b = a++; i64 main() { return 0; }`, "initializer of global variable 'b' must be a constant")
+	suite.ErrorGenerateProgramK(`i64 f() { return 1; } i64 b = f(); i64 main() { return 0; }`, "initializer of global variable 'b' must be a constant")
+
+	suite.ErrorGenerateProgramK(`
+	i64 a;
+	f64 a;
+
+	i64 main() {
+		return 0;
+	}
+	`, "global variable 'a' already exists")
+}
+
 func (suite *SrcTestSuite) TestPointer() {
 	src := `
 	i8* malloc(i64 size);
@@ -271,6 +423,81 @@ func (suite *SrcTestSuite) TestStruct() {
 	suite.EqualProgramK(src, "30,40,16")
 }
 
+func (suite *SrcTestSuite) TestStructLiteral() {
+	src := `
+	type struct { i64 a, i64 b, f64 c, } point;
+
+	i64 printf(i8 *fmt,... );
+
+	point origin = point{ .c = 0.5, };
+
+	point make(i64 a) {
+		return point{ .a = a, .b = a * 2, };
+	}
+
+	i64 sum(point p) {
+		return p.a + p.b;
+	}
+
+	i64 main() {
+		point p = point{ .b = 2, .a = 1, };
+		printf("%d,%d,%.1f,", p.a, p.b, p.c);
+
+		p = make(5);
+		printf("%d,%d,", p.a, p.b);
+
+		printf("%d,", sum(point{ .a = 3, .b = p.a }));
+
+		point q = point{};
+		printf("%d,%.1f", q.a + q.b, origin.c);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "1,2,0.0,5,10,8,0,0.5")
+
+	prefix := `type struct { i64 a, i64 b, } point;`
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { point p = point{ .c = 1, }; return 0; }`, "field 'c' not found in struct")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { point p = point{ .a = 1, .a = 2, }; return 0; }`, "field 'a' is initialized more than once")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { point p = point{ .a = 1.0, }; return 0; }`, "cannot assign f64 to field 'a' of type i64")
+	suite.ErrorGenerateProgramK(`type i64 num; i64 main() { num n = num{ .a = 1, }; return 0; }`, "cannot use struct literal for non-struct type num")
+}
+
+func (suite *SrcTestSuite) TestInitializerList() {
+	suite.EqualExprK(`[3]i64 xs = {1, 2, 3};`, `"%d,%d,%d", xs[0], xs[1], xs[2]`, "1,2,3")
+	suite.EqualExprK(`[4]i64 xs = {5, [2] = 2, 3};`, `"%d,%d,%d,%d", xs[0], xs[1], xs[2], xs[3]`, "5,0,2,3")
+	suite.EqualExprK(`[3]i64 xs = {1,};`, `"%d,%d,%d", xs[0], xs[1], xs[2]`, "1,0,0")
+	suite.EqualExprK(`[3][2]i64 m = {{1, 2}, {3, 4}};`, `"%d,%d,%d", m[0][1], m[1][0], m[2][1]`, "2,3,0")
+	suite.EqualExprK(`i64 a = 7; [2]i64 xs = {a, a * 2};`, `"%d,%d", xs[0], xs[1]`, "7,14")
+	suite.EqualExprK(`[6]i8 s = "hello";`, `"%s,%d", &s[0], s[5]`, "hello,0")
+	suite.EqualExprK(`[2][4]i8 s = {"abc", "de"};`, `"%s,%s", &s[0][0], &s[1][0]`, "abc,de")
+
+	src := `
+	type struct { i64 v, [2]i64 sub, } S;
+
+	i64 printf(i8 *fmt,... );
+
+	[2]i64 g = {1, 2};
+	[1]S a = {{1, {2, 3}}};
+
+	i64 main() {
+		S s = a[0];
+		[2]i64 sub = s.sub;
+		printf("%d,%d,%d,%d,%d", g[0], g[1], s.v, sub[0], sub[1]);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "1,2,1,2,3")
+
+	suite.ErrorGenerateExprK(`[2]i64 xs = {1, 2, 3};`, "too many elements in initializer for array of length 2")
+	suite.ErrorGenerateExprK(`[2]i64 xs = {[2] = 1};`, "index 2 in initializer is out of bounds for array of length 2")
+	suite.ErrorGenerateExprK(`i64 i = 1; [2]i64 xs = {[i] = 1};`, "array index in initializer must be an integer constant")
+	suite.ErrorGenerateExprK(`[2]i64 xs = {1.0};`, "cannot assign f64 to i64")
+	suite.ErrorGenerateExprK(`[3]i8 s = "hello";`, "string of length 5 is too long for array of length 3")
+	suite.ErrorGenerateExprK(`i64 x = {1};`, "cannot use initializer list for i64")
+}
+
 func (suite *SrcTestSuite) TestAvoidLeftRecursion() {
 	suite.EqualExprK(`i64 x = 2 * 3 * 4;`, `"%d", x`, "24")
 	suite.EqualExprK(`i64 x = 2;`, `"%d", ++++x`, "4")
@@ -294,6 +521,865 @@ func (suite *SrcTestSuite) TestAvoidLeftRecursion() {
 	suite.EqualExprC(`int x = 3 - -3;`, `"%d", x`, "6")
 }
 
+func (suite *SrcTestSuite) TestShortCircuit() {
+	src := `
+	i64 printf(i8 *fmt,... );
+
+	bool check(i64 x, bool result) {
+		printf("%d,", x);
+		return result;
+	}
+
+	i64 main() {
+		if (check(1, false) && check(2, true)) {
+			printf("no,");
+		}
+
+		if (check(3, true) || check(4, true)) {
+			printf("yes,");
+		}
+
+		bool b = check(5, true) && check(6, false) || check(7, true);
+		bool c = false || true && false;
+
+		printf("%d,%d", b, c);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "1,3,yes,5,6,7,1,0")
+
+	suite.ErrorGenerateExprK(`bool b = true && 1;`, "incompatible types bool and i64")
+}
+
+func (suite *SrcTestSuite) TestShift() {
+	suite.EqualExprK(`i64 x = 1 << 10;`, `"%d", x`, "1024")
+	suite.EqualExprK(`i64 x = -16 >> 2;`, `"%d", x`, "-4")
+	suite.EqualExprK(`u64 x = (u64)(-16) >> 60;`, `"%d", x`, "15")
+	suite.EqualExprK(`i32 x = (i32)3 << 2;`, `"%d", x`, "12")
+	suite.EqualExprK(`i8 n = (i8)3; i64 x = 1 << n;`, `"%d", x`, "8")
+	suite.EqualExprK(`i64 x = 1; x <<= 4; x >>= 1;`, `"%d", x`, "8")
+
+	suite.ErrorGenerateExprK(`f64 x = 1.0 << 2.0;`, "operation << is not implemented for f64")
+}
+
+func (suite *SrcTestSuite) TestContextualLiterals() {
+	suite.EqualExprK(`i32 x = 5; i8 y = -128; u8 z = 255;`, `"%d,%d,%d", x, (i64)y, (u64)z`, "5,-128,255")
+	suite.EqualExprK(`u64 x = 18446744073709551615;`, `"%llu", x`, "18446744073709551615")
+	suite.EqualExprK(`f32 x = 1.5; f64 y = 2;`, `"%.1f,%.1f", (f64)x, y`, "1.5,2.0")
+	suite.EqualExprK(`i16 x = 1; x = x + 2; x += 3; x = 10 - x;`, `"%d", x`, "4")
+	suite.EqualExprK(`i8 x = 1 + 2 * 3;`, `"%d", x`, "7")
+	suite.EqualExprK(`u32 x = 7; bool b = 7 == x;`, `"%d", b`, "1")
+	suite.EqualExprK(`[3]u8 xs = {1, 2, 255};`, `"%d", xs[2]`, "255")
+	// untyped arithmetic is folded exactly with integer semantics before it gets a type
+	suite.EqualExprK(`f64 y = 1 / 2; f64 z = 1.0 / 2;`, `"%.1f,%.1f", y, z`, "0.0,0.5")
+	suite.EqualExprK(`u8 x = 300 - 100; i8 y = -128 / 2 + 1; u64 z = 1 << 63;`, `"%d,%d,%lu", (u64)x, (i64)y, z`, "200,-63,9223372036854775808")
+	suite.EqualExprK(`i64 x = 7 % 3 - 7 / -2; i8 y = 1000 - 999;`, `"%d,%d", x, (i64)y`, "4,1")
+	suite.EqualExprK(`myint x = 5; myint y = x + 1;`, `"%d", (i64)y`, "6", testing.Declare("type i32 myint;"))
+
+	src := `
+	i64 printf(i8 *fmt,... );
+
+	type struct { i8 a, f32 b, } pair;
+
+	i16 g = -300;
+
+	u8 twice(u8 x) {
+		return x * 2;
+	}
+
+	i64 main() {
+		pair p = pair{ .a = 1, .b = 2, };
+		printf("%d,%d,%d,%.1f", (i64)g, (u64)twice(100), (i64)p.a, (f64)p.b);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "-300,200,1,2.0")
+
+	suite.ErrorGenerateExprK(`i8 x = 300;`, "constant 300 overflows i8")
+	suite.ErrorGenerateExprK(`u8 x = -1;`, "constant -1 overflows u8")
+	suite.ErrorGenerateExprK(`i32 x = 0; x = 2147483648;`, "constant 2147483648 overflows i32")
+	suite.ErrorGenerateExprK(`i64 x = 9223372036854775808;`, "constant 9223372036854775808 overflows i64")
+	suite.ErrorGenerateExprK(`i32 x = 1.5;`, "cannot assign f64 to i32")
+	suite.ErrorGenerateProgramK(`u8 f() { return 256; }`, "constant 256 overflows u8")
+	suite.ErrorGenerateProgramK(`u8 f() { return 1.0; }`, "function 'f' must return a value of type 'u8'")
+	suite.ErrorGenerateExprK(`i8 a = 100 + 100;`, "constant 100 + 100 overflows i8")
+	suite.ErrorGenerateExprK(`u8 b = 0 - 1;`, "constant 0 - 1 overflows u8")
+	suite.ErrorGenerateExprK(`i64 x = 9223372036854775807 + 1;`, "constant 9223372036854775807 + 1 overflows i64")
+	suite.ErrorGenerateExprK(`i64 x = 1 / 0;`, "division by zero")
+	suite.ErrorGenerateExprK(`i64 z = (i64)7 / (i64)0;`, "division by zero")
+	suite.ErrorGenerateExprK(`u64 x = 7; u64 z = x % (u64)0;`, "division by zero")
+	suite.ErrorGenerateExprK(`i64 x = 1 << 65;`, "invalid shift count 65")
+	suite.ErrorGenerateExprK(`myint x = 1.5;`, "cannot assign f64 to myint", testing.Declare("type i32 myint;"))
+
+	// an untyped conditional has side effects, so the operands are still evaluated from left to right
+	src = `
+	i64 printf(i8 *fmt,... );
+
+	bool first() {
+		printf("a");
+		return true;
+	}
+
+	i64 second() {
+		printf("b");
+		return 1;
+	}
+
+	i64 main() {
+		i64 x = (first() ? 1 : 2) + second();
+		printf("%d", x);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "ab2")
+}
+
+func (suite *SrcTestSuite) TestPromotion() {
+	suite.EqualExprK(`i32 a = -3; i64 b = 10; i64 c = a + b;`, `"%d", c`, "7")
+	suite.EqualExprK(`u8 a = 200; i64 b = 100; i64 c = a + b;`, `"%d", c`, "300")
+	suite.EqualExprK(`u8 a = 200; u32 b = 100; u32 c = a * b;`, `"%d", c`, "20000")
+	suite.EqualExprK(`i8 a = -1; u64 b = 1; bool c = a < (i64)b;`, `"%d", c`, "1")
+	suite.EqualExprK(`i32 a = 3; f64 b = 0.5; f64 c = a * b;`, `"%.1f", c`, "1.5")
+	suite.EqualExprK(`u16 a = 3; f32 b = 0.5; f64 c = (f64)(a + b);`, `"%.1f", c`, "3.5")
+	suite.EqualExprK(`f32 a = 1.5; f64 b = 2.0; f64 c = a + b;`, `"%.1f", c`, "3.5")
+	suite.EqualExprK(`i16 a = 5; i64 b = 5; bool c = a == b;`, `"%d", c`, "1")
+	suite.EqualExprK(`u8 a = 12; u64 b = 10; u64 c = a & b;`, `"%d", c`, "8")
+	// explicit widening extends by the signedness of the source, like in C
+	suite.EqualExprK(`u8 y = 255; i64 x = (i64)y;`, `"%d", x`, "255")
+	suite.EqualExprK(`i8 y = -1; u64 x = (u64)y;`, `"%lu", x`, "18446744073709551615")
+
+	suite.ErrorGenerateExprK(`i32 a = 1; u32 b = 1; bool c = a < b;`, "incompatible types i32 and u32, use an explicit cast")
+	suite.ErrorGenerateExprK(`i8 a = 1; u64 b = 1; bool c = a < b;`, "incompatible types i8 and u64, use an explicit cast")
+	suite.ErrorGenerateExprK(`i32 a = 1; i64 b = 1; i32 c = a + b;`, "cannot assign i64 to i32")
+	suite.ErrorGenerateExprK(`hello a = (hello)1; i32 b = 1; bool c = a == b;`, "incompatible types hello and i32", testing.Declare("type i64 hello;"))
+}
+
+func (suite *SrcTestSuite) TestCasting() {
+	suite.EqualExprK(`i64 i = -7; f64 f = (f64)i;`, `"%.1f", f`, "-7.0")
+	suite.EqualExprK(`u8 i = 250; f32 f = (f32)i;`, `"%.1f", (f64)f`, "250.0")
+	suite.EqualExprK(`f64 f = -2.7; i32 i = (i32)f; u8 u = (u8)2.7;`, `"%d,%d", i, (i64)u`, "-2,2")
+	suite.EqualExprK(`bool a = (bool)10; bool b = (bool)0; bool c = (bool)0.5; bool d = (bool)0.0;`, `"%d,%d,%d,%d", a, b, c, d`, "1,0,1,0")
+	suite.EqualExprK(`i64 a = (i64)true; u8 b = (u8)false; f64 c = (f64)true;`, `"%d,%d,%.1f", a, (i64)b, c`, "1,0,1.0")
+	suite.EqualExprK(`i64 x = 42; i64 *p = &x; i64 addr = (i64)p; i64 *q = (i64*)addr;`, `"%d,%d", *q, p == q`, "42,1")
+	suite.EqualExprK(`i64 x = 42; i64 *p = &x; bool b = (bool)p; bool n = (bool)(i64*)0;`, `"%d,%d", b, n`, "1,0")
+
+	prefix := `type struct { i64 a, } st;`
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { st s; i64 x = (i64)s; return 0; }`, "cannot cast st to i64")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { i64 x = 0; st s = (st)x; return 0; }`, "cannot cast i64 to st")
+	suite.ErrorGenerateExprK(`f64 x = 1.0; i64 *p = (i64*)x;`, "cannot cast f64 to i64*")
+}
+
+func (suite *SrcTestSuite) TestFnCallChecks() {
+	src := `
+	i64 printf(i8 *fmt,... );
+
+	f64 scale(f64 x, u8 factor) {
+		return x * factor;
+	}
+
+	i64 main() {
+		i8 small = -5;
+		u16 medium = 60000;
+		f32 single = 0.25;
+		bool flag = true;
+
+		printf("%.1f,%d,%d,%.2f,%d", scale(1.5, 2), small, medium, single, flag);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "3.0,-5,60000,0.25,1")
+
+	prefix := `i64 add(i64 a, i64 b) { return a + b; } i64 printf(i8 *fmt,... );`
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { return add(1); }`, "function 'add' expects 2 arguments, got 1")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { return add(1, 2, 3); }`, "function 'add' expects 2 arguments, got 3")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { printf(); return 0; }`, "function 'printf' expects at least 1 argument, got 0")
+	suite.ErrorGenerateProgramK(`i64 neg(i64 a) { return -a; } i64 main() { return neg(); }`, "function 'neg' expects 1 argument, got 0")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { return add(1, 2.5); }`, "cannot use f64 as i64 for parameter 'b' of function 'add'")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { i32 x = 1; return add(x, 256); }`, "cannot use i32 as i64 for parameter 'a' of function 'add'")
+	suite.ErrorGenerateProgramK(`void f(u8 x) {} i64 main() { f(256); return 0; }`, "constant 256 overflows u8")
+
+	// the error points at the argument, not at the call
+	src = prefix + `
+	i64 main() {
+		return add(1,
+			2.5);
+	}
+	`
+	suite.ErrorGenerateProgramK(src, "\t\t\t2.5);\n\t\t\t^: cannot use f64 as i64 for parameter 'b' of function 'add'")
+}
+
+func (suite *SrcTestSuite) TestDeclarationOrder() {
+	src := `
+	i64 main() {
+		printf("%d,%d,%d", twice(21), isEven(10), isOdd(7));
+
+		return 0;
+	}
+
+	i64 twice(i64 x) {
+		return x * 2;
+	}
+
+	bool isOdd(i64 n);
+
+	bool isEven(i64 n) {
+		if (n == 0) {
+			return true;
+		}
+
+		return isOdd(n - 1);
+	}
+
+	bool isOdd(i64 n) {
+		if (n == 0) {
+			return false;
+		}
+
+		return isEven(n - 1);
+	}
+
+	i64 printf(i8 *fmt,... );
+	`
+	suite.EqualProgramK(src, "42,1,1")
+
+	suite.ErrorGenerateProgramK(`i64 f(i64 a); i32 f(i64 a) { return 0; }`, "conflicting types for function 'f'")
+	suite.ErrorGenerateProgramK(`i64 f(i64 a); i64 f(i64 a, i64 b) { return 0; }`, "conflicting types for function 'f'")
+	suite.ErrorGenerateProgramK(`i64 f() { return 0; } i64 f() { return 1; }`, "function 'f' already has a body")
+}
+
+func (suite *SrcTestSuite) TestUnreachable() {
+	src := `
+	i64 printf(i8 *fmt,... );
+
+	i64 sign(i64 x) {
+		if (x < 0) {
+			return -1;
+		} else {
+			return 1;
+		}
+	}
+
+	void greet(bool loud) {
+		if (loud) {
+			printf("HI,");
+			return;
+		}
+
+		printf("hi,");
+	}
+
+	i64 main() {
+		greet(true);
+		greet(false);
+
+		while (true) {
+			break;
+			printf("never");
+		}
+
+		printf("%d,%d", sign(-5), sign(5));
+
+		return 0;
+		printf("never");
+	}
+	`
+	suite.EqualProgramK(src, "HI,hi,-1,1")
+
+	// the loop is left only by return, so there is no missing return after it
+	src = `
+	i64 printf(i8 *fmt,... );
+
+	i64 f(i64 x) {
+		while (true) {
+			if (x > 3) return x;
+			x++;
+		}
+	}
+
+	i64 g(i64 x) {
+		for (;;) {
+			if (x < 0) return x;
+			x--;
+		}
+	}
+
+	i64 main() {
+		printf("%d,%d", f(1), g(2));
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "4,-1")
+
+	suite.WarningExprK(`return 0; i64 x = 1;`, "unreachable code")
+	suite.WarningExprK(`for (i64 i = 0; i < 3; i++) { continue; { i64 y = 2; } } return 0;`, "unreachable code")
+	suite.WarningProgramK(`i64 main() { if (true) { return 0; } else { return 1; } return 2; }`, "unreachable code")
+
+	suite.ErrorGenerateProgramK(`i64 f() { } i64 main() { return f(); }`, "missing return in function 'f'")
+	suite.ErrorGenerateProgramK(`i64 f(bool b) { if (b) { return 1; } } i64 main() { return f(true); }`, "missing return in function 'f'")
+	suite.ErrorGenerateProgramK(`i64 f(bool b) { while (b) { return 1; } } i64 main() { return f(true); }`, "missing return in function 'f'")
+	suite.ErrorGenerateProgramK(`i64 f() { while (true) { break; } } i64 main() { return f(); }`, "missing return in function 'f'")
+}
+
+func (suite *SrcTestSuite) TestConditional() {
+	suite.EqualExprK(`i64 x = 3;`, `"%d", x > 2 ? 10 : 20`, "10")
+	suite.EqualExprK(`i64 x = 1;`, `"%d", x > 2 ? 10 : x > 0 ? 30 : 40`, "30")
+	suite.EqualExprK(`i8 x = true ? 1 : 2;`, `"%d", sizeof x`, "1")
+	suite.EqualExprK(`i32 a = 2;`, `"%.1f", a > 1 ? 1.5 : a`, "1.5")
+	suite.EqualExprK(`u8 a = 200;`, `"%d", a < 100 ? a : 255`, "255")
+
+	src := `
+	i64 printf(i8 *fmt,... );
+
+	i64 calls = 0;
+
+	i64 side(i64 x) {
+		calls++;
+		return x;
+	}
+
+	i64 g = false ? 1 : 2;
+
+	i64 main() {
+		i64 r = side(1) > 0 ? side(5) : side(6);
+		printf("%d,%d,%d", r, calls, g);
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "5,2,2")
+
+	suite.ErrorGenerateExprK(`i64 x = 1 ? 2 : 3;`, "cannot use i64 as condition")
+	suite.ErrorGenerateExprK(`i64 x = 1; i64 *p = &x; i64 y = true ? x : p;`, "incompatible types i64 and i64* in conditional expression")
+}
+
+func (suite *SrcTestSuite) TestAssignExpr() {
+	suite.EqualExprK(`i64 a = 1; i64 b = 2; a = b = 7;`, `"%d,%d", a, b`, "7,7")
+	suite.EqualExprK(`i64 a = 1; i64 b = 2; a += b *= 3;`, `"%d,%d", a, b`, "7,6")
+	suite.EqualExprK(`i64 a = 1; i64 b = (a = 5) + 1;`, `"%d,%d", a, b`, "5,6")
+
+	src := `
+	i64 printf(i8 *fmt,... );
+
+	i64 n = 3;
+
+	i64 next() {
+		n--;
+		return n;
+	}
+
+	i64 main() {
+		i64 c;
+		i64 sum = 0;
+
+		while ((c = next()) != 0) {
+			sum += c;
+		}
+
+		for (i64 i = 0; i < 3; i += 1) {
+			sum = sum * 10;
+		}
+
+		printf("%d,%d", sum, c);
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "3000,0")
+
+	suite.ErrorGenerateExprK(`i64 a = 1; (a + 1) = 2;`, "cannot assign to non-variable")
+	suite.ErrorGenerateExprK(`i64 a = 1; i64 b = 2; (a = b) = 3;`, "cannot assign to non-variable")
+	// the result has the type of the assigned variable
+	suite.ErrorGenerateExprK(`i8 a = 0; i64 b = 0; b = a = 3;`, "cannot assign i8 to i64")
+}
+
+func (suite *SrcTestSuite) TestMultipleDeclarators() {
+	suite.EqualExprK(`i64 a = 1, b, c = a + 2;`, `"%d,%d,%d", a, b, c`, "1,0,3")
+	suite.EqualExprK(`i64 x = 5, *p = &x, **pp = &p;`, `"%d", **pp`, "5")
+	suite.EqualExprK(`i8 s[6] = "hello", *t = &s[1];`, `"%s,%s", t, &s[0]`, "ello,hello")
+	suite.EqualExprK(`i64 sum = 0; for (i64 i = 0, j = 10; i < j; i++) { sum += j - i; j--; }`, `"%d", sum`, "30")
+
+	suite.ErrorGenerateExprK(`i64 a = 1, a = 2;`, "variable 'a' already exists in this scope")
+}
+
+func (suite *SrcTestSuite) TestDoWhile() {
+	// the body runs at least once
+	suite.EqualExprK(`i64 n = 0; do { n++; } while (false);`, `"%d", n`, "1")
+	suite.EqualExprK(`i64 n = 0; i64 sum = 0; do sum += n++; while (n < 5);`, `"%d,%d", n, sum`, "5,10")
+
+	// continue checks the condition, break leaves the loop
+	suite.EqualExprK(`i64 n = 0; i64 odd = 0; do { n++; if (n % 2 == 0) { continue; } odd++; } while (n < 10);`, `"%d,%d", n, odd`, "10,5")
+	suite.EqualExprK(`i64 n = 0; do { if (n == 3) { break; } n++; } while (true);`, `"%d", n`, "3")
+
+	suite.ErrorGenerateExprK(`i64 n = 0; do { n++; } while (n);`, "cannot use i64 as condition")
+}
+
+func (suite *SrcTestSuite) TestSwitch() {
+	src := `
+	i64 printf(i8 *fmt,... );
+
+	i64 classify(i64 x) {
+		switch (x) {
+			case 0:
+				return 0;
+			case 1:
+			case 2:
+				return 12;
+			case -1:
+				return -1;
+			default:
+				return 99;
+		}
+	}
+
+	i64 main() {
+		printf("%d,%d,%d,%d,%d,", classify(0), classify(1), classify(2), classify(-1), classify(7));
+
+		// fall through until a break
+		u8 c = 2;
+		i64 n = 0;
+		switch (c) {
+			case 1:
+				n += 1;
+			case 2:
+				n += 10;
+			case 3:
+				n += 100;
+				break;
+			case 4:
+				n += 1000;
+		}
+		printf("%d,", n);
+
+		// without a default case, unmatched values skip the switch
+		switch (n) {
+			case 1:
+				n = 0;
+		}
+
+		// continue belongs to the enclosing loop
+		i64 odd = 0;
+		for (i64 i = 0; i < 10; i++) {
+			switch (i % 2) {
+				case 0:
+					continue;
+			}
+			odd++;
+		}
+		printf("%d,%d", n, odd);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "0,12,12,-1,99,110,110,5")
+
+	// casts of constants are constants too
+	suite.EqualExprK(`i32 k = 2; i64 r = 0; switch (k) { case (i32)1: r = 1; break; case (i32)2: r = 2; break; }`, `"%d", r`, "2")
+	suite.EqualExprK(`u8 k = 255; i64 r = 0; switch (k) { case (u8)(i64)-1: r = 1; break; }`, `"%d", r`, "1")
+	suite.EqualExprK(`i64 k = -1; i64 r = 0; switch (k) { case (i64)(i8)255: r = 1; break; case (i64)(u8)255: r = 2; break; }`, `"%d", r`, "1")
+
+	suite.ErrorGenerateExprK(`i64 x = 1; switch (x) { case 1: break; case 1: break; }`, "duplicate case label 1")
+	suite.ErrorGenerateExprK(`i64 x = 1; i64 y = 2; switch (x) { case y: break; }`, "case label must be an integer constant")
+	suite.ErrorGenerateExprK(`i64 x = 1; switch (x) { default: break; default: break; }`, "multiple default labels in switch")
+	suite.ErrorGenerateExprK(`u8 x = 1; switch (x) { case 256: break; }`, "constant 256 overflows u8")
+	suite.ErrorGenerateExprK(`f64 x = 1.0; switch (x) { case 1: break; }`, "cannot switch on f64")
+}
+
+func (suite *SrcTestSuite) TestGoto() {
+	src := `
+	i64 printf(i8 *fmt,... );
+
+	i64 find([4]i64 xs, i64 x) {
+		i64 i = 0;
+
+	again:
+		if (xs[i] == x) {
+			goto found;
+		}
+
+		i++;
+		if (i < 4) {
+			goto again;
+		}
+
+		return -1;
+
+	found:
+		return i;
+	}
+
+	void cleanup(bool fail) {
+		printf("open,");
+
+		if (fail) {
+			goto out;
+		}
+
+		printf("work,");
+
+	out:
+		printf("close,");
+	}
+
+	i64 main() {
+		[4]i64 xs = {3, 5, 7, 9};
+		printf("%d,%d,", find(xs, 7), find(xs, 4));
+
+		cleanup(false);
+		cleanup(true);
+
+		for (i64 i = 0; i < 3; i++) {
+			for (i64 j = 0; j < 3; j++) {
+				if (i * j == 2) {
+					printf("%d%d", i, j);
+					goto done;
+				}
+			}
+		}
+
+	done:
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "2,-1,open,work,close,open,close,12")
+
+	// variables live in the entry block, so jumping over a declaration is fine, and jumping back doesn't grow the stack
+	suite.EqualExprK(`goto l; i64 x = 1; l: x = 2;`, `"%d", x`, "2")
+	suite.EqualExprK(`i64 n = 0; again: [1024]i64 buf; buf[0] = n; n++; if (n < 100000) goto again;`, `"%d", n`, "100000")
+
+	// a label is part of the statement after it
+	suite.EqualExprK(`i64 x = 0; if (false) a: x++; x += 10;`, `"%d", x`, "10")
+
+	suite.ErrorGenerateExprK(`goto nowhere;`, "label 'nowhere' is not defined")
+	suite.ErrorGenerateExprK(`a: i64 x = 1; a: x++;`, "label 'a' is already defined")
+	suite.WarningExprK(`goto end; i64 x = 1; end:`, "unreachable code")
+
+	// a label after dead code is reached by a goto generated later
+	suite.EqualExprK(`i64 n = 0; goto start; back: n = n + 1; n = n + 0; start: if (n < 3) { goto back; }`, `"%d", n`, "3")
+	suite.NoWarningExprK(`i64 n = 0; goto start; back: n = n + 1; n = n + 0; start: if (n < 3) { goto back; }`)
+	suite.WarningExprK(`i64 n = 0; back: n = n + 1; if (n < 3) { goto back; n = 5; }`, "unreachable code")
+}
+
+func (suite *SrcTestSuite) TestFunctionPointers() {
+	src := `
+	i64 printf(i8 *fmt,... );
+	void qsort(i8 *base, i64 n, i64 size, fn(i8*, i8*) -> i32 cmp);
+
+	i64 add(i64 a, i64 b) {
+		return a + b;
+	}
+
+	i64 mul(i64 a, i64 b) {
+		return a * b;
+	}
+
+	i64 apply(fn(i64, i64) -> i64 op, i64 a, i64 b) {
+		return op(a, b);
+	}
+
+	i32 compare(i8 *a, i8 *b) {
+		i64 *x = (i64*)a;
+		i64 *y = (i64*)b;
+
+		if (*x < *y) {
+			return -1;
+		}
+
+		return *x > *y ? 1 : 0;
+	}
+
+	type fn(i64, i64) -> i64 binop;
+
+	fn(i64, i64) -> i64 global = mul;
+
+	i64 main() {
+		fn(i64, i64) -> i64 op = add;
+		printf("%d,", op(2, 3));
+
+		op = &mul;
+		printf("%d,", (*op)(2, 3));
+
+		[2]fn(i64, i64) -> i64 table = {add, mul};
+		printf("%d,%d,", table[0](4, 5), table[1](4, 5));
+
+		// aliases are distinct types, so functions need a cast
+		binop b = (binop)add;
+		printf("%d,", b(1, 1));
+
+		printf("%d,%d,", apply(add, 6, 7), global(6, 7));
+		printf("%d,%d,", op == mul, op != mul);
+
+		[5]i64 xs = {5, 3, 9, 1, 7};
+		qsort((i8*)&xs[0], 5, sizeof(i64), compare);
+		printf("%d%d%d%d%d", xs[0], xs[1], xs[2], xs[3], xs[4]);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "5,6,9,20,2,13,42,1,0,13579")
+
+	// callbacks and arrays in structs can be called and indexed directly
+	src = `
+	i64 printf(i8 *fmt,... );
+
+	i64 add(i64 a, i64 b) {
+		return a + b;
+	}
+
+	type struct { fn(i64, i64) -> i64 cb, [3]i64 xs, } ops;
+
+	i64 main() {
+		ops s = ops{ .cb = add, };
+		ops *p = &s;
+
+		s.xs[1] = 5;
+		p->xs[2] = 7;
+		printf("%d,%d,%d,%d", s.cb(1, 2), p->cb(3, 4), s.xs[1], p->xs[2]);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "3,7,5,7")
+
+	prefix := `i64 add(i64 a, i64 b) { return a + b; } `
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { fn(i64) -> i64 op = add; return 0; }`, "cannot assign fn(i64, i64) -> i64 to fn(i64) -> i64")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { fn(i64, i64) -> i64 op = add; return op(1); }`, "function 'op' expects 2 arguments, got 1")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { fn(i64, i64) -> i64 op = add; return op(1, 2.0); }`, "cannot use f64 as i64 for parameter 2 of function 'op'")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { i64 x = 1; return x(1); }`, "cannot call x of type i64")
+}
+
+func (suite *SrcTestSuite) TestEnum() {
+	src := `
+	i64 printf(i8 *fmt,... );
+
+	type enum { RED, GREEN = 5, BLUE, } color;
+	type enum u8 { SMALL = 2, LARGE = 200 } size;
+	type enum i32 { MINUS = -2, ZERO = 0, ONE } sign;
+
+	color global = BLUE;
+
+	[LARGE]i8 buffer;
+
+	i8* name(color c) {
+		switch (c) {
+		case RED:
+			return "red";
+		case GREEN:
+			return "green";
+		default:
+			return "other";
+		}
+	}
+
+	i64 main() {
+		color c = RED;
+		printf("%s,%s,%s,", name(c), name(GREEN), name(global));
+		printf("%d,%d,%d,", (i64)RED, (i64)GREEN, (i64)BLUE);
+		printf("%d,%d,", (i64)LARGE, (i64)MINUS);
+		printf("%d,%d,", c == RED, c != RED);
+
+		[SMALL]i64 xs = {1, 2};
+		printf("%d,%d,", xs[1], sizeof buffer);
+
+		i64 i = (i64)GREEN + 1;
+		c = (color)i;
+		printf("%d,", c == BLUE);
+
+		// untyped constants adopt the enum type
+		color d = RED + 1;
+		printf("%d", (i64)d);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "red,green,other,0,5,6,200,-2,1,0,2,200,1,1")
+
+	suite.ErrorGenerateProgramK(`type enum { A, B } ab; type enum { C, D } cd; i64 main() { ab x = C; return 0; }`, "cannot assign cd to ab")
+	suite.ErrorGenerateProgramK(`type enum { A, B } ab; i64 main() { i64 x = A; return 0; }`, "cannot assign ab to i64")
+	suite.ErrorGenerateProgramK(`type enum { A, B } ab; type enum { C, D } cd; ab f() { cd x = D; return x; } i64 main() { return 0; }`, "function 'f' must return a value of type 'ab'")
+	suite.ErrorGenerateProgramK(`i64 g() { u64 y = (u64)5; return y; } i64 main() { return 0; }`, "function 'g' must return a value of type 'i64'")
+	suite.ErrorGenerateProgramK(`type enum { A, B } ab; type enum { C, D } cd; i64 main() { return (i64)(A == C); }`, "incompatible types ab and cd")
+	suite.ErrorGenerateProgramK(`type enum { A, B } ab; type enum { C, A } cd; i64 main() { return 0; }`, "enum constant 'A' already exists")
+	suite.ErrorGenerateProgramK(`type enum { A } ab; i64 A = 1; i64 main() { return 0; }`, "enum constant 'A' already exists")
+	suite.ErrorGenerateProgramK(`type enum i8 { A = 127, B } ab; i64 main() { return 0; }`, "constant 128 overflows ab")
+	suite.ErrorGenerateProgramK(`type enum u8 { A = -1 } ab; i64 main() { return 0; }`, "constant -1 overflows ab")
+	suite.ErrorGenerateProgramK(`type enum { A = 1.5 } ab; i64 main() { return 0; }`, "enum value of A must be an integer")
+	suite.ErrorGenerateProgramK(`i64 main() { [N]i64 xs; return 0; }`, "unknown constant 'N' in array length")
+	suite.ErrorGenerateProgramK(`type enum { A, B } ab; i64 main() { ab x = A; switch (x) { case (i64)0: return 1; } return 0; }`, "cannot use i64 as case label for ab")
+}
+
+func (suite *SrcTestSuite) TestUnion() {
+	src := `
+	i64 printf(i8 *fmt,... );
+
+	type union { f64 f, u64 bits, [8]u8 bytes, } number;
+	type union { [10]i8 chars, i32 n, } padded;
+	type struct { i8 tag, union { i64 i, f64 f, } value, } tagged;
+
+	number global;
+
+	void set(number *n, f64 f) {
+		n->f = f;
+	}
+
+	i64 main() {
+		number n;
+		set(&n, 1.0);
+		[8]u8 bytes = n.bytes;
+		printf("%lu,%d,", n.bits, (i64)bytes[7]);
+
+		global.bits = (u64)4611686018427387904;
+		printf("%.1f,", global.f);
+
+		printf("%d,%d,%d,", sizeof(number), sizeof(padded), sizeof(tagged));
+
+		padded p;
+		p.n = (i32)258;
+		[10]i8 chars = p.chars;
+		printf("%d%d,", (i64)chars[0], (i64)chars[1]);
+
+		tagged t;
+		t.tag = (i8)1;
+		t.value.i = 42;
+		printf("%d", t.value.i);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "4607182418800017408,63,2.0,8,12,16,21,42")
+
+	prefix := `type union { i64 a, f64 b, } u;`
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { u x; x.c = 1; return 0; }`, "field 'c' not found in union")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { u x = {1}; return 0; }`, "cannot use initializer list for u")
+	suite.ErrorGenerateProgramK(prefix+`type struct { i64 a, f64 b, } s; i64 main() { u x; s y = (s)x; return 0; }`, "cannot cast")
+}
+
+func (suite *SrcTestSuite) TestVoidPointer() {
+	src := `
+	i64 printf(i8 *fmt,... );
+	void* malloc(i64 size);
+	void free(void* ptr);
+	void* memcpy(void* dst, void* src, i64 n);
+
+	type struct { void* data, i64 size, } buffer;
+
+	i64 g = 7;
+	void* any = &g;
+
+	i64* numbers(i64 n) {
+		return malloc(n * sizeof(i64));
+	}
+
+	void swap(void *a, void *b, i64 size) {
+		void *tmp = malloc(size);
+		memcpy(tmp, a, size);
+		memcpy(a, b, size);
+		memcpy(b, tmp, size);
+		free(tmp);
+	}
+
+	i64 main() {
+		i64 *xs = numbers(2);
+		xs[0] = 1;
+		xs[1] = 2;
+		swap(xs, &xs[1], sizeof(i64));
+		printf("%d%d,", xs[0], xs[1]);
+
+		buffer b = {xs, 2};
+		i64 *ys = b.data;
+		printf("%d,", ys[1]);
+
+		[2]void* ptrs = {xs, &g};
+		i64 *p = ptrs[1];
+		i64 *q = any;
+		printf("%d,%d,", *p, *q);
+
+		void *v = xs;
+		v = &g;
+		printf("%d,%d", v == any, sizeof(void*));
+
+		free(xs);
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "21,1,7,7,1,8")
+
+	prefix := `i64 x = 1; void* p = &x; `
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { return *p; }`, "cannot dereference void*")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { i64 y = p[0]; return 0; }`, "cannot dereference void*")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { void* q = p + 1; return 0; }`, "cannot do arithmetic on void*")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { p++; return 0; }`, "cannot do arithmetic on void*")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { i64 y = p; return 0; }`, "cannot assign void* to i64")
+	suite.ErrorGenerateProgramK(prefix+`type i64* ptr; i64 main() { ptr y = p; return 0; }`, "cannot assign void* to ptr")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { p->a = 1; return 0; }`, "cannot access field of non-struct type void")
+}
+
+func (suite *SrcTestSuite) TestNull() {
+	src := `
+	i64 printf(i8 *fmt,... );
+	void* malloc(i64 size);
+
+	type struct { i64 value, node* next, } node;
+
+	node* head = null;
+
+	node* push(node *next, i64 value) {
+		node *n = malloc(sizeof(node));
+		n->value = value;
+		n->next = next;
+		return n;
+	}
+
+	node* find(node *n, i64 value) {
+		while (n != null) {
+			if (n->value == value) {
+				return n;
+			}
+
+			n = n->next;
+		}
+
+		return null;
+	}
+
+	i64 main() {
+		head = push(push(push(head, 1), 2), 3);
+
+		node *n = find(head, 2);
+		printf("%d,%d,", n->value, find(head, 4) == null);
+
+		fn(node*, i64) -> node* f = null;
+		printf("%d,", f == null);
+		f = find;
+		printf("%d,", null == f);
+
+		i64 *p = n == null ? null : &n->value;
+		printf("%d,", *p);
+
+		[4]node nodes;
+		node *first = &nodes[0];
+		node *last = &nodes[3];
+		printf("%d,%d,", last - first, first - last);
+		printf("%d%d%d%d,", first < last, first > last, first <= first, last >= first);
+
+		void *v = last;
+		printf("%d,%d", v == last, first != v);
+
+		return 0;
+	}
+	`
+	suite.EqualProgramK(src, "2,1,1,0,2,3,-3,1011,1,1")
+
+	prefix := `i64 x = 1; i8 y = 1; `
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { i64 z = null; return 0; }`, "cannot assign void* to i64")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { bool b = &x == &y; return 0; }`, "incompatible types i64* and i8*")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { i64 d = &x - &y; return 0; }`, "incompatible types i64* and i8*")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { i64 *p = &x + &x; return 0; }`, "operation + is not implemented for i64*")
+	suite.ErrorGenerateProgramK(prefix+`i64 main() { void *p = null; i64 d = p - p; return 0; }`, "cannot do arithmetic on void*")
+}
+
 func TestSrcTestSuite(t *t.T) {
 	suite.Run(t, new(SrcTestSuite))
 }
@@ -2,14 +2,22 @@ package ast
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/alecthomas/participle/v2/lexer"
 	"github.com/klvnptr/k/utils"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
 )
 
 func (a *ExprStmt) String() []string {
 	return []string{"expr " + a.Expr.String() + ";"}
 }
 
+func (a *ExprStmt) Position() lexer.Position {
+	return a.Pos
+}
+
 func (a *ExprStmt) Generate() error {
 	_, err := a.Expr.Value()
 
@@ -28,13 +36,17 @@ func (d *DeclStmt) String() []string {
 	return []string{"decl " + d.Type.String() + " " + d.Ident + ";"}
 }
 
+func (d *DeclStmt) Position() lexer.Position {
+	return d.Pos
+}
+
 func (d *DeclStmt) Generate() error {
 	typ, err := d.Type.IRType()
 	if err != nil {
 		return err
 	}
 
-	ptr := d.Scope.BasicBlock().NewAlloca(typ)
+	ptr := d.Scope.CurrentFunction().alloca(typ)
 
 	v := &Variable{
 		Ident: d.Ident,
@@ -49,7 +61,12 @@ func (d *DeclStmt) Generate() error {
 	}
 
 	if d.Expr != nil {
-		expr, err := d.Expr.Value()
+		expr, err := valueAs(d.Expr, d.Type)
+		if err != nil {
+			return err
+		}
+
+		expr, err = convert(d.Scope, expr, d.Type)
 		if err != nil {
 			return err
 		}
@@ -64,50 +81,45 @@ func (d *DeclStmt) Generate() error {
 	return nil
 }
 
-func (a *AssignStmt) String() []string {
-	return []string{"assign " + a.Left.String() + " = " + a.Right.String() + ";"}
-}
-
-func (a *AssignStmt) Generate() error {
-	left, err := a.Left.Value()
-	if err != nil {
-		return err
-	}
-
-	right, err := a.Right.Value()
-	if err != nil {
-		return err
-	}
-
-	if !left.Type.Equals(right.Type) {
-		return utils.WithPos(fmt.Errorf("cannot assign %s to %s", right.Type.String(), left.Type.String()), a.Scope.Current().File, a.Pos)
-	}
-
-	if left.Ptr == nil {
-		return utils.WithPos(fmt.Errorf("cannot assign to non-variable"), a.Scope.Current().File, a.Pos)
-	}
-
-	a.Scope.BasicBlock().NewStore(right.Value, left.Ptr)
-
-	return nil
-}
-
 func (r *ReturnStmt) String() []string {
 	return []string{"return " + r.Expr.String() + ";"}
 }
 
+func (r *ReturnStmt) Position() lexer.Position {
+	return r.Pos
+}
+
 func (r *ReturnStmt) Generate() error {
+	fn := r.Scope.CurrentFunction()
+
 	if r.Expr == nil {
+		if !fn.ReturnType.IsVoid() {
+			return utils.WithPos(fmt.Errorf("function '%s' must return a value of type '%s'", fn.Name, fn.ReturnType.String()), r.Scope.Current().File, r.Pos)
+		}
+
 		r.Scope.BasicBlock().NewRet(nil)
 		return nil
 	}
 
-	val, err := r.Expr.Value()
+	if fn.ReturnType.IsVoid() {
+		return utils.WithPos(fmt.Errorf("function '%s' must not return a value", fn.Name), r.Scope.Current().File, r.Pos)
+	}
+
+	val, err := valueAs(r.Expr, fn.ReturnType)
+	if err != nil {
+		return err
+	}
 
+	val, err = convert(r.Scope, val, fn.ReturnType)
 	if err != nil {
 		return err
 	}
 
+	// distinct types with the same llvm type (e.g. enums, or i64 and u64) don't mix
+	if !val.Type.Equals(fn.ReturnType) {
+		return utils.WithPos(fmt.Errorf("function '%s' must return a value of type '%s'", fn.Name, fn.ReturnType.String()), r.Scope.Current().File, r.Pos)
+	}
+
 	r.Scope.BasicBlock().NewRet(val.Value)
 
 	return nil
@@ -145,6 +157,10 @@ func (i *IfStmt) String() []string {
 	return lines
 }
 
+func (i *IfStmt) Position() lexer.Position {
+	return i.Pos
+}
+
 func (i *IfStmt) Generate() error {
 	expr, err := i.Condition.Value()
 	if err != nil {
@@ -164,10 +180,8 @@ func (i *IfStmt) Generate() error {
 
 	// then block
 	i.Scope.SetBasicBlock(thenBlock)
-	for _, stmt := range i.Then {
-		if err := stmt.Generate(); err != nil {
-			return err
-		}
+	if err := generateStatements(i.Scope, i.Then); err != nil {
+		return err
 	}
 	// if the last statement in the then block doesn't terminate the block, add a branch to the merge block
 	// this is necessary because all basic blocks must terminate
@@ -177,10 +191,8 @@ func (i *IfStmt) Generate() error {
 
 	// else block
 	i.Scope.SetBasicBlock(elseBlock)
-	for _, stmt := range i.Else {
-		if err := stmt.Generate(); err != nil {
-			return err
-		}
+	if err := generateStatements(i.Scope, i.Else); err != nil {
+		return err
 	}
 
 	if i.Scope.BasicBlock().Term == nil {
@@ -210,6 +222,10 @@ func (w *WhileStmt) String() []string {
 	return lines
 }
 
+func (w *WhileStmt) Position() lexer.Position {
+	return w.Pos
+}
+
 func (w *WhileStmt) Generate() error {
 	entryBlock := w.Scope.CurrentFunction().Ptr.NewBlock(w.Scope.CurrentModule().GenerateID("while.entry"))
 	loopBlock := w.Scope.CurrentFunction().Ptr.NewBlock(w.Scope.CurrentModule().GenerateID("while.loop"))
@@ -231,19 +247,383 @@ func (w *WhileStmt) Generate() error {
 	w.Scope.BasicBlock().NewCondBr(expr.Value, loopBlock, mergeBlock)
 
 	// loop block
+	loop := &Loop{Break: mergeBlock, Continue: entryBlock, Parent: w.Scope.CurrentLoop()}
+	w.Scope.SetCurrentLoop(loop)
+
 	w.Scope.SetBasicBlock(loopBlock)
-	for _, stmt := range w.Body {
-		if err := stmt.Generate(); err != nil {
-			return err
-		}
+	if err := generateStatements(w.Scope, w.Body); err != nil {
+		return err
 	}
 
 	if w.Scope.BasicBlock().Term == nil {
 		w.Scope.BasicBlock().NewBr(entryBlock)
 	}
 
+	w.Scope.SetCurrentLoop(loop.Parent)
+
 	// merge block
 	w.Scope.SetBasicBlock(mergeBlock)
 
 	return nil
 }
+
+func (d *DoWhileStmt) String() []string {
+	lines := []string{"do"}
+
+	for _, stmt := range d.Body {
+		bodyLines := stmt.String()
+
+		// if the statement is a block, don't indent it
+		if _, ok := stmt.(*Block); !ok {
+			d.Scope.Current().PrefixLines(bodyLines)
+		}
+
+		lines = append(lines, bodyLines...)
+	}
+
+	return append(lines, "while ("+d.Condition.String()+");")
+}
+
+func (d *DoWhileStmt) Position() lexer.Position {
+	return d.Pos
+}
+
+func (d *DoWhileStmt) Generate() error {
+	bodyBlock := d.Scope.CurrentFunction().Ptr.NewBlock(d.Scope.CurrentModule().GenerateID("do.body"))
+	condBlock := d.Scope.CurrentFunction().Ptr.NewBlock(d.Scope.CurrentModule().GenerateID("do.cond"))
+	mergeBlock := d.Scope.CurrentFunction().Ptr.NewBlock(d.Scope.CurrentModule().GenerateID("do.merge"))
+
+	d.Scope.BasicBlock().NewBr(bodyBlock)
+
+	// body block, continue jumps to the condition, so it's checked before the next iteration
+	loop := &Loop{Break: mergeBlock, Continue: condBlock, Parent: d.Scope.CurrentLoop()}
+	d.Scope.SetCurrentLoop(loop)
+
+	d.Scope.SetBasicBlock(bodyBlock)
+	if err := generateStatements(d.Scope, d.Body); err != nil {
+		return err
+	}
+
+	if d.Scope.BasicBlock().Term == nil {
+		d.Scope.BasicBlock().NewBr(condBlock)
+	}
+
+	d.Scope.SetCurrentLoop(loop.Parent)
+
+	// condition block
+	d.Scope.SetBasicBlock(condBlock)
+	expr, err := d.Condition.Value()
+	if err != nil {
+		return err
+	}
+
+	if !expr.Type.IsBool() {
+		return utils.WithPos(fmt.Errorf("cannot use %s as condition", expr.Type.String()), d.Scope.Current().File, d.Pos)
+	}
+
+	d.Scope.BasicBlock().NewCondBr(expr.Value, bodyBlock, mergeBlock)
+
+	// merge block
+	d.Scope.SetBasicBlock(mergeBlock)
+
+	return nil
+}
+
+func (f *ForStmt) String() []string {
+	init := ";"
+	if len(f.Init) > 0 {
+		init = strings.Join(StatementLikeList(f.Init).String(), " ")
+	}
+
+	cond := ""
+	if f.Condition != nil {
+		cond = f.Condition.String()
+	}
+
+	post := ""
+	if f.Post != nil {
+		post = strings.TrimSuffix(strings.Join(f.Post.String(), " "), ";")
+	}
+
+	lines := []string{fmt.Sprintf("for (%s %s; %s)", init, cond, post)}
+
+	for _, stmt := range f.Body {
+		bodyLines := stmt.String()
+
+		// if the statement is a block, don't indent it
+		if _, ok := stmt.(*Block); !ok {
+			f.Scope.Current().PrefixLines(bodyLines)
+		}
+
+		lines = append(lines, bodyLines...)
+	}
+
+	return lines
+}
+
+func (f *ForStmt) Position() lexer.Position {
+	return f.Pos
+}
+
+func (f *ForStmt) Generate() error {
+	initBlock := f.Scope.CurrentFunction().Ptr.NewBlock(f.Scope.CurrentModule().GenerateID("for.init"))
+	condBlock := f.Scope.CurrentFunction().Ptr.NewBlock(f.Scope.CurrentModule().GenerateID("for.cond"))
+	bodyBlock := f.Scope.CurrentFunction().Ptr.NewBlock(f.Scope.CurrentModule().GenerateID("for.body"))
+	postBlock := f.Scope.CurrentFunction().Ptr.NewBlock(f.Scope.CurrentModule().GenerateID("for.post"))
+	mergeBlock := f.Scope.CurrentFunction().Ptr.NewBlock(f.Scope.CurrentModule().GenerateID("for.merge"))
+
+	f.Scope.BasicBlock().NewBr(initBlock)
+
+	// init block
+	f.Scope.SetBasicBlock(initBlock)
+	if err := generateStatements(f.Scope, f.Init); err != nil {
+		return err
+	}
+
+	f.Scope.BasicBlock().NewBr(condBlock)
+
+	// condition block, a missing condition loops forever
+	f.Scope.SetBasicBlock(condBlock)
+	if f.Condition != nil {
+		expr, err := f.Condition.Value()
+		if err != nil {
+			return err
+		}
+
+		if !expr.Type.IsBool() {
+			return utils.WithPos(fmt.Errorf("cannot use %s as condition", expr.Type.String()), f.Scope.Current().File, f.Pos)
+		}
+
+		f.Scope.BasicBlock().NewCondBr(expr.Value, bodyBlock, mergeBlock)
+	} else {
+		f.Scope.BasicBlock().NewBr(bodyBlock)
+	}
+
+	// body block, continue jumps to the post block
+	loop := &Loop{Break: mergeBlock, Continue: postBlock, Parent: f.Scope.CurrentLoop()}
+	f.Scope.SetCurrentLoop(loop)
+
+	f.Scope.SetBasicBlock(bodyBlock)
+	if err := generateStatements(f.Scope, f.Body); err != nil {
+		return err
+	}
+
+	if f.Scope.BasicBlock().Term == nil {
+		f.Scope.BasicBlock().NewBr(postBlock)
+	}
+
+	f.Scope.SetCurrentLoop(loop.Parent)
+
+	// post block
+	f.Scope.SetBasicBlock(postBlock)
+	if f.Post != nil {
+		if err := f.Post.Generate(); err != nil {
+			return err
+		}
+	}
+
+	f.Scope.BasicBlock().NewBr(condBlock)
+
+	// merge block
+	f.Scope.SetBasicBlock(mergeBlock)
+
+	return nil
+}
+
+func (s *SwitchStmt) String() []string {
+	lines := []string{"switch (" + s.Expr.String() + ")"}
+
+	for _, c := range s.Cases {
+		if c.Value != nil {
+			lines = append(lines, "case "+c.Value.String()+":")
+		} else {
+			lines = append(lines, "default:")
+		}
+
+		for _, stmt := range c.Body {
+			lines = append(lines, s.Scope.Current().PrefixLines(stmt.String())...)
+		}
+	}
+
+	return lines
+}
+
+func (s *SwitchStmt) Position() lexer.Position {
+	return s.Pos
+}
+
+func (s *SwitchStmt) Generate() error {
+	expr, err := s.Expr.Value()
+	if err != nil {
+		return err
+	}
+
+	if !expr.Type.IsInt() && !expr.Type.IsUInt() {
+		return utils.WithPos(fmt.Errorf("cannot switch on %s", expr.Type.String()), s.Scope.Current().File, s.Pos)
+	}
+
+	fn := s.Scope.CurrentFunction()
+
+	var defaultBlock *ir.Block
+
+	blocks := make([]*ir.Block, len(s.Cases))
+	cases := []*ir.Case{}
+	seen := map[string]bool{}
+
+	for i, c := range s.Cases {
+		blocks[i] = fn.Ptr.NewBlock(s.Scope.CurrentModule().GenerateID("switch.case"))
+
+		if c.Value == nil {
+			if defaultBlock != nil {
+				return utils.WithPos(fmt.Errorf("multiple default labels in switch"), s.Scope.Current().File, c.Pos)
+			}
+
+			defaultBlock = blocks[i]
+			continue
+		}
+
+		label, err := valueAs(c.Value, expr.Type)
+		if err != nil {
+			return err
+		}
+
+		k, ok := label.Value.(*constant.Int)
+		if !ok {
+			return utils.WithPos(fmt.Errorf("case label must be an integer constant"), s.Scope.Current().File, c.Pos)
+		}
+
+		if !label.Type.Equals(expr.Type) {
+			return utils.WithPos(fmt.Errorf("cannot use %s as case label for %s", label.Type.String(), expr.Type.String()), s.Scope.Current().File, c.Pos)
+		}
+
+		if seen[k.X.String()] {
+			return utils.WithPos(fmt.Errorf("duplicate case label %s", k.X.String()), s.Scope.Current().File, c.Pos)
+		}
+
+		seen[k.X.String()] = true
+
+		cases = append(cases, ir.NewCase(k, blocks[i]))
+	}
+
+	mergeBlock := fn.Ptr.NewBlock(s.Scope.CurrentModule().GenerateID("switch.merge"))
+
+	// without a default label, unmatched values skip the switch
+	if defaultBlock == nil {
+		defaultBlock = mergeBlock
+	}
+
+	s.Scope.BasicBlock().NewSwitch(expr.Value, defaultBlock, cases...)
+
+	// break leaves the switch, but continue still belongs to the enclosing loop
+	loop := &Loop{Break: mergeBlock, Parent: s.Scope.CurrentLoop()}
+	if loop.Parent != nil {
+		loop.Continue = loop.Parent.Continue
+	}
+
+	s.Scope.SetCurrentLoop(loop)
+
+	for i, c := range s.Cases {
+		s.Scope.SetBasicBlock(blocks[i])
+		if err := generateStatements(s.Scope, c.Body); err != nil {
+			return err
+		}
+
+		// like in C, cases without a break fall through to the next one
+		if s.Scope.BasicBlock().Term == nil {
+			if i+1 < len(blocks) {
+				s.Scope.BasicBlock().NewBr(blocks[i+1])
+			} else {
+				s.Scope.BasicBlock().NewBr(mergeBlock)
+			}
+		}
+	}
+
+	s.Scope.SetCurrentLoop(loop.Parent)
+
+	// merge block
+	s.Scope.SetBasicBlock(mergeBlock)
+
+	return nil
+}
+
+func (b *BreakStmt) String() []string {
+	return []string{"break;"}
+}
+
+func (b *BreakStmt) Position() lexer.Position {
+	return b.Pos
+}
+
+func (b *BreakStmt) Generate() error {
+	loop := b.Scope.CurrentLoop()
+	if loop == nil {
+		return utils.WithPos(fmt.Errorf("break statement not within a loop or switch"), b.Scope.Current().File, b.Pos)
+	}
+
+	b.Scope.BasicBlock().NewBr(loop.Break)
+
+	return nil
+}
+
+func (c *ContinueStmt) String() []string {
+	return []string{"continue;"}
+}
+
+func (c *ContinueStmt) Position() lexer.Position {
+	return c.Pos
+}
+
+func (c *ContinueStmt) Generate() error {
+	loop := c.Scope.CurrentLoop()
+	if loop == nil || loop.Continue == nil {
+		return utils.WithPos(fmt.Errorf("continue statement not within a loop"), c.Scope.Current().File, c.Pos)
+	}
+
+	c.Scope.BasicBlock().NewBr(loop.Continue)
+
+	return nil
+}
+
+func (l *LabelStmt) String() []string {
+	return []string{l.Name + ":"}
+}
+
+func (l *LabelStmt) Position() lexer.Position {
+	return l.Pos
+}
+
+func (l *LabelStmt) Generate() error {
+	fn := l.Scope.CurrentFunction()
+
+	label := fn.label(l.Name, l.Pos)
+	if label.Defined {
+		return utils.WithPos(fmt.Errorf("label '%s' is already defined", l.Name), l.Scope.Current().File, l.Pos)
+	}
+
+	label.Defined = true
+
+	// the previous statements fall through to the label
+	if l.Scope.BasicBlock().Term == nil {
+		l.Scope.BasicBlock().NewBr(label.Block)
+	}
+
+	l.Scope.SetBasicBlock(label.Block)
+
+	return nil
+}
+
+func (g *GotoStmt) String() []string {
+	return []string{"goto " + g.Name + ";"}
+}
+
+func (g *GotoStmt) Position() lexer.Position {
+	return g.Pos
+}
+
+func (g *GotoStmt) Generate() error {
+	label := g.Scope.CurrentFunction().label(g.Name, g.Pos)
+
+	g.Scope.BasicBlock().NewBr(label.Block)
+
+	return nil
+}
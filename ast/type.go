@@ -33,6 +33,7 @@ type Type struct {
 	_array   *ArrayType
 	_struct  *StructType
 	_pointer *Type
+	_func    *FuncType
 	_alias   string
 
 	_cached types.Type
@@ -73,6 +74,10 @@ func (t *Type) Pointer() *Type {
 	return t.AliasedType()._pointer
 }
 
+func (t *Type) Func() *FuncType {
+	return t.AliasedType()._func
+}
+
 func (t *Type) Alias() string {
 	return t._alias
 }
@@ -92,6 +97,8 @@ func (t *Type) String() string {
 		return t.Struct().String()
 	} else if t.IsPointer() {
 		return t.Pointer().String() + "*"
+	} else if t.IsFunc() {
+		return t.Func().String()
 	} else {
 		// TODO: better error handling
 		panic("unknown type")
@@ -143,6 +150,10 @@ func (t *Type) IRType() (types.Type, error) {
 	} else if t.IsArray() {
 		at := t.Array()
 
+		if at.Const != "" && t.Scope.CurrentModule().FindConstant(at.Const) == nil {
+			return nil, utils.WithPos(fmt.Errorf("unknown constant '%s' in array length", at.Const), t.Scope.Current().File, t.Pos)
+		}
+
 		if at.Len <= 0 {
 			return nil, utils.WithPos(fmt.Errorf("array length must be greater than 0"), t.Scope.Current().File, t.Pos)
 		}
@@ -187,20 +198,48 @@ func (t *Type) IRType() (types.Type, error) {
 				typ.Fields = append(typ.Fields, ft)
 			}
 
+			if st.Union {
+				typ.Fields = unionFields(typ.Fields)
+			}
+
 			final = typ
 		}
 	} else if t.IsPointer() {
 		typ := t.Pointer()
+
+		// void* is an opaque pointer to bytes, like in C
 		if typ.IsVoid() {
-			return nil, utils.WithPos(fmt.Errorf("cannot have pointer to void"), t.Scope.Current().File, t.Pos)
+			final = types.NewPointer(types.I8)
+		} else {
+			irType, err := typ.IRType()
+			if err != nil {
+				return nil, err
+			}
+
+			final = types.NewPointer(irType)
 		}
+	} else if t.IsFunc() {
+		ft := t.Func()
 
-		irType, err := typ.IRType()
+		params := []types.Type{}
+		for _, p := range ft.Params {
+			irType, err := p.IRType()
+			if err != nil {
+				return nil, err
+			}
+
+			params = append(params, irType)
+		}
+
+		ret, err := ft.Return.IRType()
 		if err != nil {
 			return nil, err
 		}
 
-		final = types.NewPointer(irType)
+		sig := types.NewFunc(ret, params...)
+		sig.Variadic = ft.Variadic
+
+		final = types.NewPointer(sig)
 	} else {
 		return nil, utils.WithPos(fmt.Errorf("unknown type"), t.Scope.Current().File, t.Pos)
 	}
@@ -327,10 +366,19 @@ func (t *Type) IsStruct() bool {
 	return t.Struct() != nil
 }
 
+// IsVoidPointer is true for void*, which converts implicitly to and from other pointers
+func (t *Type) IsVoidPointer() bool {
+	return t.IsPointer() && t.Pointer().IsVoid()
+}
+
 func (t *Type) IsPointer() bool {
 	return t.Pointer() != nil
 }
 
+func (t *Type) IsFunc() bool {
+	return t.Func() != nil
+}
+
 func (t *Type) IsAlias() bool {
 	return t.Alias() != ""
 }
@@ -405,6 +453,8 @@ func (t *Type) Equals(o *Type) bool {
 		return t.Struct().Equals(o.Struct())
 	} else if t.IsPointer() && o.IsPointer() {
 		return t.Pointer().Equals(o.Pointer())
+	} else if t.IsFunc() && o.IsFunc() {
+		return t.Func().Equals(o.Func())
 	} else {
 		return false
 	}
@@ -420,6 +470,8 @@ func (t *Type) AliasOf(o *Type) bool {
 		return t.Struct().Equals(o.Struct())
 	} else if t.IsPointer() && o.IsPointer() {
 		return t.Pointer().Equals(o.Pointer())
+	} else if t.IsFunc() && o.IsFunc() {
+		return t.Func().Equals(o.Func())
 	} else {
 		return false
 	}
@@ -7,10 +7,12 @@ import (
 type ArrayType struct {
 	Type *Type
 	Len  int
+	// Const is the enum constant the length was given by, if any
+	Const string
 }
 
 func (at *ArrayType) String() string {
-	return fmt.Sprintf("%s[%d]", at.Type.String(), at.Len)
+	return fmt.Sprintf("[%d]%s", at.Len, at.Type.String())
 }
 
 func (at *ArrayType) Equals(o *ArrayType) bool {
@@ -0,0 +1,189 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/klvnptr/k/utils"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// isArithmetic is true for numeric types which can be converted to each other implicitly.
+// Aliases are distinct types, so they are never converted implicitly.
+func (t *Type) isArithmetic() bool {
+	return !t.IsAlias() && (t.IsInt() || t.IsUInt() || t.IsFloat())
+}
+
+// promote returns the common type of two arithmetic types, or nil if there is none:
+//   - integers of the same signedness are widened to the wider one
+//   - an unsigned integer is converted to a signed one, only if the signed one is wider,
+//     so every value of the unsigned type fits
+//   - integers are converted to floats, and floats are widened to the wider one
+//
+// Mixing signed and unsigned integers of the same width would lose values either way,
+// so that needs an explicit cast.
+func promote(a, b *Type) *Type {
+	if !a.isArithmetic() || !b.isArithmetic() {
+		return nil
+	}
+
+	if a.Equals(b) {
+		return a
+	}
+
+	wider := func(a, b *Type) *Type {
+		if a.BasicSize() >= b.BasicSize() {
+			return a
+		}
+
+		return b
+	}
+
+	switch {
+	case a.IsFloat() && b.IsFloat():
+		return wider(a, b)
+	case a.IsFloat():
+		return a
+	case b.IsFloat():
+		return b
+	case a.IsInt() == b.IsInt():
+		return wider(a, b)
+	case a.IsInt() && a.BasicSize() > b.BasicSize():
+		return a
+	case b.IsInt() && b.BasicSize() > a.BasicSize():
+		return b
+	}
+
+	return nil
+}
+
+// convert applies the implicit conversions of assignments, calls and returns, v is returned as it is if there is none.
+// Only void* converts implicitly, to and from any other pointer, aliases still need an explicit cast.
+func convert(scope ScopeLike, v *Value, typ *Type) (*Value, error) {
+	if v.Type.Equals(typ) || v.Type.IsAlias() || typ.IsAlias() || !v.Type.IsPointer() || !typ.IsPointer() {
+		return v, nil
+	}
+
+	if !v.Type.IsVoidPointer() && !typ.IsVoidPointer() {
+		return v, nil
+	}
+
+	irType, err := typ.IRType()
+	if err != nil {
+		return nil, err
+	}
+
+	result := v.Value
+
+	// globals can only be initialized with constants
+	if c, ok := v.Value.(constant.Constant); ok && !c.Type().Equal(irType) {
+		result = constant.NewBitCast(c, irType)
+	} else if !v.Value.Type().Equal(irType) {
+		result = scope.BasicBlock().NewBitCast(v.Value, irType)
+	}
+
+	return &Value{Type: typ, Value: result}, nil
+}
+
+// cast converts v to typ, or returns an error if that's not possible.
+func cast(scope ScopeLike, pos lexer.Position, v *Value, typ *Type) (*Value, error) {
+	if v.Type.Equals(typ) {
+		return &Value{Type: typ, Ptr: v.Ptr, Value: v.Value}, nil
+	}
+
+	if v.Type.AliasOf(typ) {
+		return &Value{Type: typ, Value: v.Value}, nil
+	}
+
+	var result value.Value
+
+	bb := scope.BasicBlock()
+
+	targetIRType, err := typ.IRType()
+	if err != nil {
+		return nil, err
+	}
+
+	if typ.IsBool() {
+		if v.Type.IsInt() || v.Type.IsUInt() {
+			result = bb.NewICmp(enum.IPredNE, v.Value, constant.NewInt(v.Type.LLVMIntType(), 0))
+		} else if v.Type.IsFloat() {
+			// unordered comparison, so NaN is true like in C
+			result = bb.NewFCmp(enum.FPredUNE, v.Value, constant.NewFloat(v.Type.LLVMFloatType(), 0))
+		} else if v.Type.IsPointer() || v.Type.IsFunc() {
+			irType, err := v.Type.IRType()
+			if err != nil {
+				return nil, err
+			}
+
+			result = bb.NewICmp(enum.IPredNE, v.Value, constant.NewNull(irType.(*types.PointerType)))
+		}
+	} else if typ.IsInt() || typ.IsUInt() {
+		if c, ok := v.Value.(*constant.Int); ok && (v.Type.IsInt() || v.Type.IsUInt() || v.Type.IsBool()) {
+			// constant integers are converted exactly, so they stay constants, e.g. in case labels like (i32)2
+			result = convertLLInt(c, v.Type.IsInt(), targetIRType.(*types.IntType))
+		} else if v.Type.IsInt() || v.Type.IsUInt() {
+			// the extension depends on the signedness of the source, e.g. (i64)(u8)255 is 255
+			if typ.BasicSize() > v.Type.BasicSize() && v.Type.IsInt() {
+				result = bb.NewSExt(v.Value, targetIRType)
+			} else if typ.BasicSize() > v.Type.BasicSize() {
+				result = bb.NewZExt(v.Value, targetIRType)
+			} else if typ.BasicSize() < v.Type.BasicSize() {
+				result = bb.NewTrunc(v.Value, targetIRType)
+			} else {
+				result = v.Value
+			}
+		} else if v.Type.IsBool() {
+			// true is 1, false is 0
+			result = bb.NewZExt(v.Value, targetIRType)
+		} else if v.Type.IsFloat() && typ.IsInt() {
+			result = bb.NewFPToSI(v.Value, targetIRType)
+		} else if v.Type.IsFloat() {
+			result = bb.NewFPToUI(v.Value, targetIRType)
+		} else if v.Type.IsPointer() || v.Type.IsFunc() {
+			result = bb.NewPtrToInt(v.Value, targetIRType)
+		}
+	} else if typ.IsFloat() {
+		if v.Type.IsFloat() {
+			if typ.BasicSize() > v.Type.BasicSize() {
+				result = bb.NewFPExt(v.Value, targetIRType)
+			} else {
+				result = bb.NewFPTrunc(v.Value, targetIRType)
+			}
+		} else if v.Type.IsInt() {
+			result = bb.NewSIToFP(v.Value, targetIRType)
+		} else if v.Type.IsUInt() || v.Type.IsBool() {
+			result = bb.NewUIToFP(v.Value, targetIRType)
+		}
+	} else if typ.IsPointer() || typ.IsFunc() {
+		// function pointers are pointers as well, so they can be converted to other pointers
+		if v.Type.IsPointer() || v.Type.IsFunc() {
+			result = bb.NewBitCast(v.Value, targetIRType)
+		} else if v.Type.IsInt() || v.Type.IsUInt() {
+			result = bb.NewIntToPtr(v.Value, targetIRType)
+		} else if v.Type.IsArray() && typ.IsPointer() {
+			if v.Ptr == nil {
+				return nil, utils.WithPos(fmt.Errorf("cannot cast a non-variable array to a pointer"), scope.Current().File, pos)
+			}
+
+			irType, err := v.Type.IRType()
+			if err != nil {
+				return nil, err
+			}
+
+			result = bb.NewGetElementPtr(irType, v.Ptr, NewLLInt(32, 0), NewLLInt(32, 0))
+		}
+	}
+
+	if result == nil {
+		return nil, utils.WithPos(fmt.Errorf("cannot cast %s to %s", v.Type.String(), typ.String()), scope.Current().File, pos)
+	}
+
+	return &Value{
+		Type:  typ,
+		Value: foldLL(scope, result),
+	}, nil
+}
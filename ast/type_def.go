@@ -2,8 +2,43 @@ package ast
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/klvnptr/k/utils"
 )
 
 func (td *TypeDef) String() []string {
+	if td.Constants != nil {
+		members := []string{}
+		for _, c := range td.Constants {
+			members = append(members, c.String())
+		}
+
+		return []string{fmt.Sprintf("type enum %s { %s } %s", td.Type.String(), strings.Join(members, ", "), td.Alias)}
+	}
+
 	return []string{fmt.Sprintf("type %s %s", td.Type.String(), td.Alias)}
 }
+
+func (c *EnumConstant) String() string {
+	if c.Value == nil {
+		return c.Ident
+	}
+
+	return fmt.Sprintf("%s = %s", c.Ident, c.Value.String())
+}
+
+// Constant returns the member as a constant of the enum type, it fails if the value doesn't fit the underlying type
+func (c *EnumConstant) Constant() (*Value, error) {
+	if c.Value == nil {
+		return nil, utils.WithPos(fmt.Errorf("enum value of %s must be an integer", c.Ident), c.Scope.Current().File, c.Pos)
+	}
+
+	number := &ConstantNumberOp{
+		Constant: c.Value.String(),
+		Scope:    c.Scope,
+		Pos:      c.Pos,
+	}
+
+	return number.integer(c.Type, c.Value)
+}
@@ -0,0 +1,41 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FuncType is the type of functions used as values, e.g. fn(i8*, i8*) -> i32.
+// Its values are pointers to functions.
+type FuncType struct {
+	Params   []*Type
+	Variadic bool
+	Return   *Type
+}
+
+func (ft *FuncType) String() string {
+	params := []string{}
+	for _, p := range ft.Params {
+		params = append(params, p.String())
+	}
+
+	if ft.Variadic {
+		params = append(params, "...")
+	}
+
+	return fmt.Sprintf("fn(%s) -> %s", strings.Join(params, ", "), ft.Return.String())
+}
+
+func (ft *FuncType) Equals(o *FuncType) bool {
+	if len(ft.Params) != len(o.Params) || ft.Variadic != o.Variadic {
+		return false
+	}
+
+	for i, p := range ft.Params {
+		if !p.Equals(o.Params[i]) {
+			return false
+		}
+	}
+
+	return ft.Return.Equals(o.Return)
+}
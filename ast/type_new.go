@@ -28,6 +28,29 @@ func NewTypeStruct(scope ScopeLike, pos lexer.Position, fields ...*StructField)
 	}
 }
 
+func NewTypeUnion(scope ScopeLike, pos lexer.Position, fields ...*StructField) *Type {
+	return &Type{
+		_struct: &StructType{
+			Fields: fields,
+			Union:  true,
+		},
+		Scope: scope,
+		Pos:   pos,
+	}
+}
+
+func NewTypeFunc(scope ScopeLike, pos lexer.Position, params []*Type, variadic bool, ret *Type) *Type {
+	return &Type{
+		_func: &FuncType{
+			Params:   params,
+			Variadic: variadic,
+			Return:   ret,
+		},
+		Scope: scope,
+		Pos:   pos,
+	}
+}
+
 func (t *Type) NewPointer() *Type {
 	return &Type{
 		_pointer: t,
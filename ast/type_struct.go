@@ -3,6 +3,8 @@ package ast
 import (
 	"fmt"
 	"strings"
+
+	"github.com/llir/llvm/ir/types"
 )
 
 type StructField struct {
@@ -12,6 +14,8 @@ type StructField struct {
 
 type StructType struct {
 	Fields []*StructField
+	// fields of a union share the same memory
+	Union bool
 }
 
 func (st *StructField) String() string {
@@ -25,7 +29,15 @@ func (st *StructType) FindField(field string) (int, *StructField, error) {
 		}
 	}
 
-	return 0, nil, fmt.Errorf("field '%s' not found in struct", field)
+	return 0, nil, fmt.Errorf("field '%s' not found in %s", field, st.Kind())
+}
+
+func (st *StructType) Kind() string {
+	if st.Union {
+		return "union"
+	}
+
+	return "struct"
 }
 
 func (st *StructType) String() string {
@@ -35,11 +47,11 @@ func (st *StructType) String() string {
 		fields = append(fields, f.String())
 	}
 
-	return fmt.Sprintf("struct { %s, }", strings.Join(fields, ", "))
+	return fmt.Sprintf("%s { %s, }", st.Kind(), strings.Join(fields, ", "))
 }
 
 func (st *StructType) Equals(o *StructType) bool {
-	if len(st.Fields) != len(o.Fields) {
+	if st.Union != o.Union || len(st.Fields) != len(o.Fields) {
 		return false
 	}
 
@@ -51,3 +63,30 @@ func (st *StructType) Equals(o *StructType) bool {
 
 	return true
 }
+
+// unionFields lays out a union as its most aligned member followed by padding up to the size of the largest member,
+// fields are accessed by casting the pointer to the union
+func unionFields(fields []types.Type) []types.Type {
+	var first types.Type
+	var firstSize, size, align uint64 = 0, 0, 1
+
+	for _, f := range fields {
+		fs, fa := LLLayout(f)
+
+		if first == nil || fa > align {
+			first, firstSize, align = f, fs, fa
+		}
+
+		if fs > size {
+			size = fs
+		}
+	}
+
+	result := []types.Type{first}
+
+	if padding := alignTo(size, align) - firstSize; padding > 0 {
+		result = append(result, types.NewArray(padding, types.I8))
+	}
+
+	return result
+}
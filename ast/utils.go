@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"math/big"
 	"strings"
 )
 
@@ -14,6 +15,48 @@ func (s StatementLikeList) String() []string {
 	return lines
 }
 
+// valueAs evaluates expr as typ, if the type of expr depends on its context
+func valueAs(expr ExpressionLike, typ *Type) (*Value, error) {
+	if c, ok := expr.(ContextualLike); ok {
+		return c.ValueAs(typ)
+	}
+
+	return expr.Value()
+}
+
+func isUntyped(expr ExpressionLike) bool {
+	c, ok := expr.(ContextualLike)
+	return ok && c.IsUntyped()
+}
+
+// foldNumber returns the exact value of an untyped number or arithmetic on untyped numbers, e.g. 1 + 2
+// It returns false for anything else.
+func foldNumber(expr ExpressionLike) (*big.Int, float64, bool, error) {
+	switch e := expr.(type) {
+	case *ConstantNumberOp:
+		i, f, err := e.parse()
+		return i, f, err == nil, err
+	case *BinaryOp:
+		if e.IsUntyped() {
+			return e.fold()
+		}
+	}
+
+	return nil, 0, false, nil
+}
+
+// isConstant is true for untyped expressions without side effects, e.g. 1 + 2 or null, but not b() ? 1 : 2
+func isConstant(expr ExpressionLike) bool {
+	switch e := expr.(type) {
+	case *ConstantNumberOp, *ConstantNullOp:
+		return true
+	case *BinaryOp:
+		return e.IsUntyped() && isConstant(e.Left) && isConstant(e.Right)
+	default:
+		return false
+	}
+}
+
 type ExpressionLikeList []ExpressionLike
 
 func (e ExpressionLikeList) String() string {
@@ -7,7 +7,35 @@ import (
 )
 
 func (e *Expr) Transform(scope ast.ScopeLike) ast.ExpressionLike {
-	return e.LogicalExpr.Transform(scope)
+	return e.AssignExpr.Transform(scope)
+}
+
+func (ae *AssignExpr) Transform(scope ast.ScopeLike) ast.ExpressionLike {
+	if ae.Op == "" {
+		return ae.Left.Transform(scope)
+	}
+
+	return &ast.AssignOp{
+		Left:  ae.Left.Transform(scope),
+		Op:    ae.Op,
+		Right: ae.Right.Transform(scope),
+		Scope: scope,
+		Pos:   ae.Pos,
+	}
+}
+
+func (ce *ConditionalExpr) Transform(scope ast.ScopeLike) ast.ExpressionLike {
+	if ce.Then == nil {
+		return ce.Condition.Transform(scope)
+	}
+
+	return &ast.ConditionalOp{
+		Condition: ce.Condition.Transform(scope),
+		Then:      ce.Then.Transform(scope),
+		Else:      ce.Else.Transform(scope),
+		Scope:     scope,
+		Pos:       ce.Pos,
+	}
 }
 
 func (le *LogicalExpr) Transform(scope ast.ScopeLike) ast.ExpressionLike {
@@ -149,7 +177,7 @@ func (ce *CastingExpr) Transform(scope ast.ScopeLike) ast.ExpressionLike {
 
 	return &ast.CastingOp{
 		Type:  ce.Type.Transform(scope),
-		Expr:  ce.Expr.Transform(scope),
+		Expr:  ce.Cast.Transform(scope),
 		Scope: scope,
 		Pos:   ce.Pos,
 	}
@@ -160,9 +188,19 @@ func (pe *PrefixExpr) Transform(scope ast.ScopeLike) ast.ExpressionLike {
 		return pe.Next.Transform(scope)
 	}
 
+	expr := pe.Expr.Transform(scope)
+
+	// fold the sign into number literals, so they stay untyped constants, e.g. i8 x = -1;
+	if number, ok := expr.(*ast.ConstantNumberOp); ok && pe.Op == "-" && number.Sign == "" {
+		number.Sign = "-"
+		number.Pos = pe.Pos
+
+		return number
+	}
+
 	return &ast.UnaryOp{
 		Op:    pe.Op,
-		Expr:  pe.Expr.Transform(scope),
+		Expr:  expr,
 		Scope: scope,
 		Pos:   pe.Pos,
 	}
@@ -191,27 +229,35 @@ func (ae *AccessorExpr) Transform(scope ast.ScopeLike) ast.ExpressionLike {
 	head := ae.Head.Transform(scope)
 
 	for _, tail := range ae.Tail {
-		deref := false
-		if tail.Op == "->" {
-			deref = true
-		}
+		if tail.Op != "" {
+			head = &ast.AccessorOp{
+				Expr:        head,
+				Field:       tail.Field,
+				Dereference: tail.Op == "->",
+				Scope:       scope,
+				Pos:         tail.Pos,
+			}
 
-		head = &ast.AccessorOp{
-			Expr:        head,
-			Field:       tail.Field,
-			Dereference: deref,
-			Scope:       scope,
-			Pos:         tail.Pos,
+			continue
 		}
-	}
 
-	return head
-}
+		if tail.Call {
+			args := make([]ast.ExpressionLike, len(tail.Args))
 
-func (ie *IndexExpr) Transform(scope ast.ScopeLike) ast.ExpressionLike {
-	head := ie.Head.Transform(scope)
+			for i, arg := range tail.Args {
+				args[i] = arg.Transform(scope)
+			}
+
+			head = &ast.FnCallOp{
+				Callee: head,
+				Args:   args,
+				Scope:  scope,
+				Pos:    tail.Pos,
+			}
+
+			continue
+		}
 
-	for _, tail := range ie.Tail {
 		head = &ast.IndexOp{
 			Expr:      head,
 			IndexExpr: tail.Index.Transform(scope),
@@ -274,6 +320,13 @@ func (fce *FnCallExpr) Transform(scope ast.ScopeLike) ast.ExpressionLike {
 
 func (pe *PrimaryExpr) Transform(scope ast.ScopeLike) ast.ExpressionLike {
 	switch {
+	case pe.Struct != nil:
+		return pe.Struct.Transform(scope)
+	case pe.Null:
+		return &ast.ConstantNullOp{
+			Scope: scope,
+			Pos:   pe.Pos,
+		}
 	case pe.Variable != "":
 		if pe.Variable == "true" || pe.Variable == "false" {
 			return &ast.ConstantBoolOp{
@@ -334,3 +387,22 @@ func (pe *PrimaryExpr) Transform(scope ast.ScopeLike) ast.ExpressionLike {
 		panic("unknown primary expression")
 	}
 }
+
+func (se *StructExpr) Transform(scope ast.ScopeLike) ast.ExpressionLike {
+	fields := make([]*ast.StructLiteralField, len(se.StructFields))
+
+	for i, f := range se.StructFields {
+		fields[i] = &ast.StructLiteralField{
+			Ident: f.Field,
+			Expr:  f.Expr.Transform(scope),
+			Pos:   f.Pos,
+		}
+	}
+
+	return &ast.StructLiteralOp{
+		Type:   ast.NewTypeAlias(scope, se.Pos, se.Alias),
+		Fields: fields,
+		Scope:  scope,
+		Pos:    se.Pos,
+	}
+}
@@ -11,7 +11,7 @@ func BuildLexer() *lexer.StatefulDefinition {
 			{Name: `CharStart`, Pattern: `'`, Action: lexer.Push("Char")},
 			{Name: "Number", Pattern: `(\d*\.)?\d+`, Action: nil},
 			{Name: "BasicType", Pattern: `\b(bool|void|i8|i16|i32|i64|u8|u16|u32|u64|f32|f64)\b`, Action: nil},
-			{Name: "Keyword", Pattern: `\b(if|else|while|type|return|sizeof|const|struct)\b`, Action: nil},
+			{Name: "Keyword", Pattern: `\b(if|else|do|while|for|switch|case|default|break|continue|goto|type|return|sizeof|const|struct|union|enum|null)\b`, Action: nil},
 			{Name: "Ident", Pattern: `\w+`, Action: nil},
 			// source: https://github.com/alecthomas/participle#stateful-lexer
 			{Name: "Punct", Pattern: `[-[!@#$%^&*()+_={}\|:;"'<,>.?/]|]`, Action: nil},
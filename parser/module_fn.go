@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"math/big"
+
 	"github.com/klvnptr/k/ast"
 )
 
@@ -8,6 +10,7 @@ func (m *Module) Transform(scope *ast.Scope) *ast.Module {
 	module := &ast.Module{
 		ModuleTypeDefs: []*ast.TypeDef{},
 		LocalTypes:     []*ast.TypeDef{},
+		Globals:        []*ast.Global{},
 		Functions:      []*ast.Function{},
 		Scope:          scope,
 		Pos:            m.Pos,
@@ -17,6 +20,10 @@ func (m *Module) Transform(scope *ast.Scope) *ast.Module {
 		module.LocalTypes = append(module.LocalTypes, td.Transform(module))
 	}
 
+	for _, g := range m.Globals {
+		module.Globals = append(module.Globals, g.Transform(module))
+	}
+
 	for _, f := range m.Functions {
 		module.Functions = append(module.Functions, f.Transform(module))
 	}
@@ -25,6 +32,10 @@ func (m *Module) Transform(scope *ast.Scope) *ast.Module {
 }
 
 func (t *TypeDef) Transform(scope ast.ScopeLike) *ast.TypeDef {
+	if t.Enum != nil {
+		return t.Enum.Transform(scope, t.Ident)
+	}
+
 	typ := t.Type.Transform(scope)
 
 	return &ast.TypeDef{
@@ -33,6 +44,69 @@ func (t *TypeDef) Transform(scope ast.ScopeLike) *ast.TypeDef {
 	}
 }
 
+// Transform assigns the values of the members, they are constants of the alias type
+func (e *Enum) Transform(scope ast.ScopeLike, alias string) *ast.TypeDef {
+	basic := ast.BasicTypeI64
+	if e.Basic != "" {
+		basic = ast.BasicType(e.Basic)
+	}
+
+	td := &ast.TypeDef{
+		Alias:     alias,
+		Type:      ast.NewTypeBasic(scope, e.Pos, basic),
+		Constants: []*ast.EnumConstant{},
+	}
+
+	next := big.NewInt(0)
+
+	for _, m := range e.Members {
+		value := next
+
+		if m.Value != "" {
+			// a value which is not an integer is reported when the module is generated
+			value, _ = new(big.Int).SetString(m.Value, 10)
+			if value != nil && m.Sign == "-" {
+				value.Neg(value)
+			}
+		}
+
+		td.Constants = append(td.Constants, &ast.EnumConstant{
+			Ident: m.Ident,
+			Value: value,
+			Type:  ast.NewTypeAlias(scope, m.Pos, alias),
+			Scope: scope,
+			Pos:   m.Pos,
+		})
+
+		next = nil
+		if value != nil {
+			next = new(big.Int).Add(value, big.NewInt(1))
+		}
+	}
+
+	return td
+}
+
+func (g *Global) Transform(scope ast.ScopeLike) *ast.Global {
+	global := &ast.Global{
+		Variable: &ast.Variable{
+			Ident: g.Declarator.Ident,
+			Type:  g.Declarator.Type.Transform(scope),
+			Pos:   g.Pos,
+		},
+		Scope: scope,
+		Pos:   g.Pos,
+	}
+
+	if g.List != nil {
+		global.Expr = g.List.Transform(scope)
+	} else if g.Expr != nil {
+		global.Expr = g.Expr.Transform(scope)
+	}
+
+	return global
+}
+
 func (f *Function) Transform(scope ast.ScopeLike) *ast.Function {
 	childScope := ast.NewScopeFromParent(scope)
 
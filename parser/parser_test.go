@@ -220,7 +220,7 @@ func (suite *ParserTestSuite) TestDecl() {
 
 	transformed := result.Transform(&ast.Block{})
 
-	suite.Equal(&ast.DeclStmt{
+	suite.Equal([]ast.StatementLike{&ast.DeclStmt{
 		Ident: "x",
 		Type:  ast.NewTypeBasic(&ast.Block{}, lexer.Position{Filename: "main.c", Offset: 0, Line: 1, Column: 1}, ast.BasicTypeI32),
 		Expr: &ast.ConstantNumberOp{
@@ -230,7 +230,223 @@ func (suite *ParserTestSuite) TestDecl() {
 		},
 		Scope: &ast.Block{},
 		Pos:   lexer.Position{Filename: "main.c", Offset: 0, Line: 1, Column: 1},
-	}, transformed)
+	}}, transformed)
+
+	result, err = p.ParseString("main.c", `[2][3]i64 x = {{1, 2, 3}, [1] = {[2] = 4,},};`)
+	suite.NoError(err)
+	suite.Len(result.Declarators[0].List.Elements, 2)
+	suite.NotNil(result.Declarators[0].List.Elements[1].Index)
+	suite.Len(result.Declarators[0].List.Elements[1].List.Elements, 1)
+
+	result, err = p.ParseString("main.c", `i64 x, *p = &x, **pp, a[2][3] = {};`)
+	suite.NoError(err)
+
+	stmts := result.Transform(&ast.Block{})
+	suite.Len(stmts, 4)
+
+	types := []string{}
+	for _, stmt := range stmts {
+		types = append(types, stmt.(*ast.DeclStmt).Type.String())
+	}
+	suite.Equal([]string{"i64", "i64*", "i64**", "[2][3]i64"}, types)
+}
+
+func (suite *ParserTestSuite) TestCast() {
+	p := parser.BuildParser[parser.CastingExpr]()
+
+	result, err := p.ParseString("main.c", `(bool)(i64*)0`)
+	suite.NoError(err)
+	suite.Equal("bool", result.Type.Basic)
+	suite.Equal("i64", result.Cast.Type.Basic)
+	suite.NotNil(result.Cast.Cast.Expr)
+}
+
+func (suite *ParserTestSuite) TestConditional() {
+	p := parser.BuildParser[parser.Expr]()
+
+	result, err := p.ParseString("main.c", `a || b ? x + 1 : y ? 2 : 3`)
+	suite.NoError(err)
+
+	// the condition binds looser than ||, and the else arm nests to the right
+	cond := result.AssignExpr.Left
+	suite.Equal("||", cond.Condition.Op)
+	suite.NotNil(cond.Then)
+	suite.NotNil(cond.Else.Then)
+	suite.Nil(cond.Else.Else.Then)
+}
+
+func (suite *ParserTestSuite) TestDoWhile() {
+	p := parser.BuildParser[parser.Stmt]()
+
+	result, err := p.ParseString("main.c", `do x--; while (x > 0);`)
+	suite.NoError(err)
+	suite.NotNil(result.DoWhileStmt)
+	suite.NotNil(result.DoWhileStmt.Body.ExprStmt)
+
+	result, err = p.ParseString("main.c", `do { x--; } while (x > 0);`)
+	suite.NoError(err)
+	suite.NotNil(result.DoWhileStmt.Body.CompoundStmt)
+}
+
+func (suite *ParserTestSuite) TestBreakContinue() {
+	p := parser.BuildParser[parser.Stmt]()
+
+	result, err := p.ParseString("main.c", `while (true) { if (x) break; continue; }`)
+	suite.NoError(err)
+
+	body := result.WhileStmt.Body.CompoundStmt.Stmts
+	suite.True(body[0].IfStmt.Then.BreakStmt.Break)
+	suite.True(body[1].ContinueStmt.Continue)
+
+	_, err = p.ParseString("main.c", `break`)
+	suite.Error(err)
+
+	_, err = p.ParseString("main.c", `continue x;`)
+	suite.Error(err)
+}
+
+func (suite *ParserTestSuite) TestSwitch() {
+	p := parser.BuildParser[parser.Stmt]()
+
+	result, err := p.ParseString("main.c", `switch (x) { case 1: case 2: y = 1; break; default: y = 2; }`)
+	suite.NoError(err)
+	suite.Len(result.SwitchStmt.Cases, 3)
+	suite.Len(result.SwitchStmt.Cases[0].Stmts, 0)
+	suite.Len(result.SwitchStmt.Cases[1].Stmts, 2)
+	suite.True(result.SwitchStmt.Cases[2].Default)
+}
+
+func (suite *ParserTestSuite) TestGoto() {
+	p := parser.BuildParser[parser.CompoundStmt]()
+
+	result, err := p.ParseString("main.c", `{ goto end; x = 1; end: return x; }`)
+	suite.NoError(err)
+	suite.Equal("end", result.Stmts[0].GotoStmt.Name)
+	suite.Equal("end", result.Stmts[2].LabelStmt.Name)
+	suite.NotNil(result.Stmts[2].LabelStmt.Stmt.ReturnStmt)
+
+	s := parser.BuildParser[parser.Stmt]()
+
+	stmt, err := s.ParseString("main.c", `if (b) a: x++;`)
+	suite.NoError(err)
+	suite.Equal("a", stmt.IfStmt.Then.LabelStmt.Name)
+	suite.NotNil(stmt.IfStmt.Then.LabelStmt.Stmt.ExprStmt)
+
+	_, err = p.ParseString("main.c", `{ end: }`)
+	suite.Error(err)
+}
+
+func (suite *ParserTestSuite) TestFuncType() {
+	p := parser.BuildParser[parser.Type]()
+
+	result, err := p.ParseString("main.c", `fn(i8*, i8*) -> i32`)
+	suite.NoError(err)
+	suite.Len(result.Func.Params, 2)
+	suite.Equal("i32", result.Func.Return.Basic)
+
+	result, err = p.ParseString("main.c", `[2]fn(i64, ...) -> void`)
+	suite.NoError(err)
+	suite.Equal([]*parser.ArrayLength{{Number: 2}}, result.Lengths)
+	suite.True(result.Func.Variadic)
+
+	e := parser.BuildParser[parser.Expr]()
+
+	expr, err := e.ParseString("main.c", `table[1](2, 3)`)
+	suite.NoError(err)
+	suite.Equal(`load(table)[1](2, 3)`, expr.Transform(&ast.Block{}).String())
+
+	suite.ParseExpr("(*fp)()")
+	suite.ParseExpr("fn(2)(3)")
+
+	// fields, indexes and calls can follow each other in any order
+	expr, err = e.ParseString("main.c", `s.cb(1, 2)`)
+	suite.NoError(err)
+	suite.Equal(`load(s).cb(1, 2)`, expr.Transform(&ast.Block{}).String())
+
+	expr, err = e.ParseString("main.c", `p->xs[1].f(2)[3]`)
+	suite.NoError(err)
+	suite.Equal(`load(p).xs[1].f(2)[3]`, expr.Transform(&ast.Block{}).String())
+}
+
+func (suite *ParserTestSuite) TestUnion() {
+	p := parser.BuildParser[parser.TypeDef]()
+
+	result, err := p.ParseString("main.c", `type union { f64 f, u64 bits, } number;`)
+	suite.NoError(err)
+	suite.True(result.Type.Struct.Union)
+	suite.Len(result.Type.Struct.Fields, 2)
+
+	result, err = p.ParseString("main.c", `type struct { i64 a, } s;`)
+	suite.NoError(err)
+	suite.False(result.Type.Struct.Union)
+
+	_, err = p.ParseString("main.c", `type union {} empty;`)
+	suite.Error(err)
+}
+
+func (suite *ParserTestSuite) TestEnum() {
+	p := parser.BuildParser[parser.TypeDef]()
+
+	result, err := p.ParseString("main.c", `type enum u8 { RED, GREEN = 4, BLUE, } color;`)
+	suite.NoError(err)
+	suite.Equal("color", result.Ident)
+	suite.Equal("u8", result.Enum.Basic)
+	suite.Len(result.Enum.Members, 3)
+	suite.Equal("4", result.Enum.Members[1].Value)
+
+	result, err = p.ParseString("main.c", `type enum { A = -1 } sign;`)
+	suite.NoError(err)
+	suite.Equal("", result.Enum.Basic)
+	suite.Equal("-", result.Enum.Members[0].Sign)
+
+	_, err = p.ParseString("main.c", `type enum {} empty;`)
+	suite.Error(err)
+
+	_, err = p.ParseString("main.c", `type enum f64 { A } real;`)
+	suite.Error(err)
+
+	t := parser.BuildParser[parser.Type]()
+
+	typ, err := t.ParseString("main.c", `[N][2]i64`)
+	suite.NoError(err)
+	suite.Equal([]*parser.ArrayLength{{Const: "N"}, {Number: 2}}, typ.Lengths)
+}
+
+func (suite *ParserTestSuite) TestNull() {
+	p := parser.BuildParser[parser.Expr]()
+
+	expr, err := p.ParseString("main.c", `p != null`)
+	suite.NoError(err)
+	suite.Equal(`load(p) != null`, expr.Transform(&ast.Block{}).String())
+
+	// null is a keyword, so it can't be declared
+	s := parser.BuildParser[parser.Stmt]()
+
+	_, err = s.ParseString("main.c", `i64 null = 3;`)
+	suite.Error(err)
+}
+
+func (suite *ParserTestSuite) TestCompoundAssign() {
+	p := parser.BuildParser[parser.Expr]()
+
+	for _, op := range []string{"=", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "<<=", ">>="} {
+		result, err := p.ParseString("main.c", "x[1] "+op+" y & 2")
+		suite.NoError(err)
+		suite.Equal(op, result.AssignExpr.Op)
+	}
+}
+
+func (suite *ParserTestSuite) TestAssignExpr() {
+	p := parser.BuildParser[parser.Expr]()
+
+	// assignments are right associative
+	result, err := p.ParseString("main.c", "a = b += c == d")
+	suite.NoError(err)
+	suite.Equal("=", result.AssignExpr.Op)
+	suite.Equal("+=", result.AssignExpr.Right.Op)
+	suite.Equal("", result.AssignExpr.Right.Right.Op)
+
+	suite.ParseExpr("(c = next()) != 0")
 }
 
 func (suite *ParserTestSuite) TestStruct() {
@@ -296,6 +512,13 @@ func (suite *ParserTestSuite) TestStruct() {
 	}
 	`)
 	suite.NoError(err)
+
+	// trailing comma is optional, and the body can be empty
+	_, err = p2.ParseString("main.c", `hello { .a = 1, .b = 2 }`)
+	suite.NoError(err)
+
+	_, err = p2.ParseString("main.c", `hello {}`)
+	suite.NoError(err)
 }
 
 func TestParserTestSuite(t *testing.T) {
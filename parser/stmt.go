@@ -5,10 +5,10 @@ import (
 )
 
 func (s *Stmt) Transform(scope ast.ScopeLike) []ast.StatementLike {
-	if s.DeclStmt != nil {
-		return []ast.StatementLike{s.DeclStmt.Transform(scope)}
-	} else if s.AssignStmt != nil {
-		return []ast.StatementLike{s.AssignStmt.Transform(scope)}
+	if s.LabelStmt != nil {
+		return append([]ast.StatementLike{s.LabelStmt.Transform(scope)}, s.LabelStmt.Stmt.Transform(scope)...)
+	} else if s.DeclStmt != nil {
+		return s.DeclStmt.Transform(scope)
 	} else if s.ExprStmt != nil {
 		return []ast.StatementLike{s.ExprStmt.Transform(scope)}
 	} else if s.ReturnStmt != nil {
@@ -19,6 +19,18 @@ func (s *Stmt) Transform(scope ast.ScopeLike) []ast.StatementLike {
 		return []ast.StatementLike{s.IfStmt.Transform(scope)}
 	} else if s.WhileStmt != nil {
 		return []ast.StatementLike{s.WhileStmt.Transform(scope)}
+	} else if s.DoWhileStmt != nil {
+		return []ast.StatementLike{s.DoWhileStmt.Transform(scope)}
+	} else if s.ForStmt != nil {
+		return []ast.StatementLike{s.ForStmt.Transform(scope)}
+	} else if s.SwitchStmt != nil {
+		return []ast.StatementLike{s.SwitchStmt.Transform(scope)}
+	} else if s.BreakStmt != nil {
+		return []ast.StatementLike{s.BreakStmt.Transform(scope)}
+	} else if s.ContinueStmt != nil {
+		return []ast.StatementLike{s.ContinueStmt.Transform(scope)}
+	} else if s.GotoStmt != nil {
+		return []ast.StatementLike{s.GotoStmt.Transform(scope)}
 	}
 
 	panic("unknown statement")
@@ -32,27 +44,74 @@ func (a *ExprStmt) Transform(scope ast.ScopeLike) ast.StatementLike {
 	}
 }
 
-func (a *DeclStmt) Transform(scope ast.ScopeLike) ast.StatementLike {
-	ds := &ast.DeclStmt{
-		Ident: a.Declarator.Ident,
-		Type:  a.Declarator.Type.Transform(scope),
-		Scope: scope,
-		Pos:   a.Pos,
-	}
+func (a *DeclStmt) Transform(scope ast.ScopeLike) []ast.StatementLike {
+	// the type is transformed only once, so all declarators share it, e.g. struct { i64 a, } x, y;
+	base := a.Type.Transform(scope)
+
+	stmts := []ast.StatementLike{}
+
+	for i, d := range a.Declarators {
+		typ := base
+
+		for j := 0; j < len(d.Pointers); j++ {
+			typ = typ.NewPointer()
+		}
+
+		// x[2][4] is an array of 2 [4] arrays, just like [2][4]i8
+		for j := len(d.Lengths) - 1; j >= 0; j-- {
+			typ = d.Lengths[j].wrap(scope, typ)
+		}
+
+		// the first declarator starts with the statement
+		pos := d.Pos
+		if i == 0 {
+			pos = a.Pos
+		}
+
+		ds := &ast.DeclStmt{
+			Ident: d.Ident,
+			Type:  typ,
+			Scope: scope,
+			Pos:   pos,
+		}
 
-	if a.Expr != nil {
-		ds.Expr = a.Expr.Transform(scope)
+		if d.List != nil {
+			ds.Expr = d.List.Transform(scope)
+		} else if d.Expr != nil {
+			ds.Expr = d.Expr.Transform(scope)
+		}
+
+		stmts = append(stmts, ds)
 	}
 
-	return ds
+	return stmts
 }
 
-func (a *AssignStmt) Transform(scope ast.ScopeLike) ast.StatementLike {
-	return &ast.AssignStmt{
-		Left:  a.Left.Transform(scope),
-		Right: a.Right.Transform(scope),
-		Scope: scope,
-		Pos:   a.Pos,
+func (il *InitializerList) Transform(scope ast.ScopeLike) ast.ExpressionLike {
+	elements := make([]*ast.InitializerElement, len(il.Elements))
+
+	for i, el := range il.Elements {
+		element := &ast.InitializerElement{
+			Pos: el.Pos,
+		}
+
+		if el.Index != nil {
+			element.Index = el.Index.Transform(scope)
+		}
+
+		if el.List != nil {
+			element.Expr = el.List.Transform(scope)
+		} else {
+			element.Expr = el.Expr.Transform(scope)
+		}
+
+		elements[i] = element
+	}
+
+	return &ast.InitializerListOp{
+		Elements: elements,
+		Scope:    scope,
+		Pos:      il.Pos,
 	}
 }
 
@@ -110,3 +169,121 @@ func (w *WhileStmt) Transform(scope ast.ScopeLike) ast.StatementLike {
 		Pos:       w.Pos,
 	}
 }
+
+func (d *DoWhileStmt) Transform(scope ast.ScopeLike) ast.StatementLike {
+	return &ast.DoWhileStmt{
+		Body:      d.Body.Transform(scope),
+		Condition: d.Condition.Transform(scope),
+		Scope:     scope,
+		Pos:       d.Pos,
+	}
+}
+
+func (f *ForStmt) Transform(scope ast.ScopeLike) ast.StatementLike {
+	// variables declared in the init clause are only visible inside the loop (like in C99),
+	// so the whole loop gets its own scope
+	loopScope := &ast.Block{
+		Stmts: []ast.StatementLike{},
+		Scope: ast.NewScopeFromParent(scope),
+		Pos:   f.Pos,
+	}
+
+	fs := &ast.ForStmt{
+		Scope: loopScope,
+		Pos:   f.Pos,
+	}
+
+	if f.Init != nil {
+		fs.Init = f.Init.Transform(loopScope)
+	}
+
+	if f.Condition != nil {
+		fs.Condition = f.Condition.Transform(loopScope)
+	}
+
+	if f.Post != nil {
+		fs.Post = &ast.ExprStmt{
+			Expr:  f.Post.Transform(loopScope),
+			Scope: loopScope,
+			Pos:   f.Post.Pos,
+		}
+	}
+
+	fs.Body = f.Body.Transform(loopScope)
+
+	return fs
+}
+
+func (fi *ForInit) Transform(scope ast.ScopeLike) []ast.StatementLike {
+	if fi.DeclStmt != nil {
+		return fi.DeclStmt.Transform(scope)
+	} else if fi.ExprStmt != nil {
+		return []ast.StatementLike{fi.ExprStmt.Transform(scope)}
+	}
+
+	panic("unknown for init statement")
+}
+
+func (s *SwitchStmt) Transform(scope ast.ScopeLike) ast.StatementLike {
+	// like in C, the cases share one scope
+	switchScope := &ast.Block{
+		Stmts: []ast.StatementLike{},
+		Scope: ast.NewScopeFromParent(scope),
+		Pos:   s.Pos,
+	}
+
+	ss := &ast.SwitchStmt{
+		Expr:  s.Expr.Transform(scope),
+		Scope: switchScope,
+		Pos:   s.Pos,
+	}
+
+	for _, c := range s.Cases {
+		sc := &ast.SwitchCase{
+			Body: []ast.StatementLike{},
+			Pos:  c.Pos,
+		}
+
+		if c.Value != nil {
+			sc.Value = c.Value.Transform(switchScope)
+		}
+
+		for _, stmt := range c.Stmts {
+			sc.Body = append(sc.Body, stmt.Transform(switchScope)...)
+		}
+
+		ss.Cases = append(ss.Cases, sc)
+	}
+
+	return ss
+}
+
+func (b *BreakStmt) Transform(scope ast.ScopeLike) ast.StatementLike {
+	return &ast.BreakStmt{
+		Scope: scope,
+		Pos:   b.Pos,
+	}
+}
+
+func (c *ContinueStmt) Transform(scope ast.ScopeLike) ast.StatementLike {
+	return &ast.ContinueStmt{
+		Scope: scope,
+		Pos:   c.Pos,
+	}
+}
+
+func (l *LabelStmt) Transform(scope ast.ScopeLike) ast.StatementLike {
+	return &ast.LabelStmt{
+		Name:  l.Name,
+		Scope: scope,
+		Pos:   l.Pos,
+	}
+}
+
+func (g *GotoStmt) Transform(scope ast.ScopeLike) ast.StatementLike {
+	return &ast.GotoStmt{
+		Name:  g.Name,
+		Scope: scope,
+		Pos:   g.Pos,
+	}
+}
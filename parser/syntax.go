@@ -6,19 +6,47 @@ import "github.com/alecthomas/participle/v2/lexer"
 // MODULE, FUNCTIONS
 
 type Module struct {
-	TypeDefs  []*TypeDef  `@@*`
-	Functions []*Function `@@*`
+	TypeDefs  []*TypeDef  `( @@`
+	Globals   []*Global   `| @@`
+	Functions []*Function `| @@ )*`
 
 	Pos lexer.Position
 }
 
 type TypeDef struct {
-	Type  *Type  `"type" @@ `
+	Enum  *Enum  `"type" ( @@`
+	Type  *Type  `| @@ )`
 	Ident string `@Ident ";"`
 
 	Pos lexer.Position
 }
 
+// Enum members without a value follow the previous one, the first one starts at 0,
+// e.g. type enum i32 { RED, GREEN = 4, BLUE } color;
+type Enum struct {
+	// must be an integer type of lexer.go BasicType, defaults to i64
+	Basic   string        `"enum" @("i8" | "i16" | "i32" | "i64" | "u8" | "u16" | "u32" | "u64")?`
+	Members []*EnumMember `"{" @@ ( "," @@ )* ","? "}"`
+
+	Pos lexer.Position
+}
+
+type EnumMember struct {
+	Ident string `@Ident`
+	Sign  string `[ "=" @"-"?`
+	Value string `@Number ]`
+
+	Pos lexer.Position
+}
+
+type Global struct {
+	Declarator *Declarator      `@@`
+	List       *InitializerList `[ "=" ( @@`
+	Expr       *Expr            `| @@ ) ] ";"`
+
+	Pos lexer.Position
+}
+
 type Function struct {
 	Declarator  *Declarator   `@@ "("`
 	Params      []*Declarator `( @@ ( "," @@ )* )?`
@@ -32,13 +60,19 @@ type Function struct {
 // STATEMENTS
 
 type Stmt struct {
-	DeclStmt     *DeclStmt     `@@`
-	AssignStmt   *AssignStmt   `| @@`
+	LabelStmt    *LabelStmt    `@@`
+	DeclStmt     *DeclStmt     `| @@`
 	ExprStmt     *ExprStmt     `| @@`
 	ReturnStmt   *ReturnStmt   `| @@`
 	CompoundStmt *CompoundStmt `| @@`
 	IfStmt       *IfStmt       `| @@`
 	WhileStmt    *WhileStmt    `| @@`
+	DoWhileStmt  *DoWhileStmt  `| @@`
+	ForStmt      *ForStmt      `| @@`
+	SwitchStmt   *SwitchStmt   `| @@`
+	BreakStmt    *BreakStmt    `| @@`
+	ContinueStmt *ContinueStmt `| @@`
+	GotoStmt     *GotoStmt     `| @@`
 
 	Pos lexer.Position
 }
@@ -50,15 +84,34 @@ type ExprStmt struct {
 }
 
 type DeclStmt struct {
-	Declarator *Declarator `@@`
-	Expr       *Expr       `[ "=" @@ ] ";"`
+	Type        *Type             `@@`
+	Declarators []*InitDeclarator `@@ ( "," @@ )* ";"`
+
+	Pos lexer.Position
+}
+
+// InitDeclarator can add its own pointers and array lengths to the declared type, e.g. i64 x, *p, a[3];
+type InitDeclarator struct {
+	Pointers string           `@"*"*`
+	Ident    string           `@Ident`
+	Lengths  []*ArrayLength   `( "[" @@ "]" )*`
+	List     *InitializerList `[ "=" ( @@`
+	Expr     *Expr            `| @@ ) ]`
+
+	Pos lexer.Position
+}
+
+// InitializerList can only be used to initialize variables, because it needs to know the type it initializes
+type InitializerList struct {
+	Elements []*InitializerElement `"{" ( @@ ( "," @@ )* ","? )? "}"`
 
 	Pos lexer.Position
 }
 
-type AssignStmt struct {
-	Left  *Expr `@@`
-	Right *Expr `"=" @@ ";"`
+type InitializerElement struct {
+	Index *Expr            `[ "[" @@ "]" "=" ]`
+	List  *InitializerList `( @@`
+	Expr  *Expr            `| @@ )`
 
 	Pos lexer.Position
 }
@@ -90,10 +143,96 @@ type WhileStmt struct {
 	Pos lexer.Position
 }
 
+// DoWhileStmt runs the body once before checking the condition
+type DoWhileStmt struct {
+	Body      *Stmt `"do" @@`
+	Condition *Expr `"while" "(" @@ ")" ";"`
+
+	Pos lexer.Position
+}
+
+type ForStmt struct {
+	Init      *ForInit `"for" "(" ( @@ | ";" )`
+	Condition *Expr    `@@? ";"`
+	Post      *Expr    `@@? ")"`
+	Body      *Stmt    `@@`
+
+	Pos lexer.Position
+}
+
+// ForInit statements are terminated by ";", so we can reuse them as they are
+type ForInit struct {
+	DeclStmt *DeclStmt `@@`
+	ExprStmt *ExprStmt `| @@`
+
+	Pos lexer.Position
+}
+
+type SwitchStmt struct {
+	Expr  *Expr         `"switch" "(" @@ ")"`
+	Cases []*SwitchCase `"{" @@* "}"`
+
+	Pos lexer.Position
+}
+
+// SwitchCase is a case or default label with the statements after it, up to the next label
+type SwitchCase struct {
+	Value   *Expr   `( "case" @@ ":"`
+	Default bool    `| @"default" ":" )`
+	Stmts   []*Stmt `@@*`
+
+	Pos lexer.Position
+}
+
+type BreakStmt struct {
+	Break bool `@"break" ";"`
+
+	Pos lexer.Position
+}
+
+type ContinueStmt struct {
+	Continue bool `@"continue" ";"`
+
+	Pos lexer.Position
+}
+
+// LabelStmt is attached to the statement after it, like in C, e.g. the body of if (b) a: x++; is both of them
+type LabelStmt struct {
+	Name string `@Ident ":"`
+	Stmt *Stmt  `@@`
+
+	Pos lexer.Position
+}
+
+type GotoStmt struct {
+	Name string `"goto" @Ident ";"`
+
+	Pos lexer.Position
+}
+
 // EXPRESSIONS
 
 type Expr struct {
-	LogicalExpr *LogicalExpr `@@`
+	AssignExpr *AssignExpr `@@`
+
+	Pos lexer.Position
+}
+
+// AssignExpr is right associative, e.g. a = b = 0 is a = (b = 0)
+// the left side must be an lvalue, which is checked during generation
+type AssignExpr struct {
+	Left  *ConditionalExpr `@@`
+	Op    string           `[ @( "=" | "+" "=" | "-" "=" | "*" "=" | "/" "=" | "%" "=" | "&" "=" | "|" "=" | "^" "=" | "<" "<" "=" | ">" ">" "=" )`
+	Right *AssignExpr      `@@ ]`
+
+	Pos lexer.Position
+}
+
+// ConditionalExpr is right associative, e.g. a ? b : c ? d : e is a ? b : (c ? d : e)
+type ConditionalExpr struct {
+	Condition *LogicalExpr     `@@`
+	Then      *Expr            `[ "?" @@`
+	Else      *ConditionalExpr `":" @@ ]`
 
 	Pos lexer.Position
 }
@@ -108,7 +247,7 @@ type LogicalExpr struct {
 
 type InclusiveOrExpr struct {
 	Left  *ExclusiveOrExpr `@@`
-	Op    string           `[ @("|")`
+	Op    string           `[ @("|" (?! "|" | "="))`
 	Right *InclusiveOrExpr `@@ ]`
 
 	Pos lexer.Position
@@ -116,7 +255,7 @@ type InclusiveOrExpr struct {
 
 type ExclusiveOrExpr struct {
 	Left  *AndExpr         `@@`
-	Op    string           `[ @("^")`
+	Op    string           `[ @("^" (?! "="))`
 	Right *ExclusiveOrExpr `@@ ]`
 
 	Pos lexer.Position
@@ -124,7 +263,7 @@ type ExclusiveOrExpr struct {
 
 type AndExpr struct {
 	Left  *EqualityExpr `@@`
-	Op    string        `[ @("&")`
+	Op    string        `[ @("&" (?! "&" | "="))`
 	Right *AndExpr      `@@ ]`
 
 	Pos lexer.Position
@@ -140,7 +279,7 @@ type EqualityExpr struct {
 
 type ComparisonExpr struct {
 	Left  *ShiftExpr      `@@`
-	Op    string          `[ @("<" "=" | ">" "=" | "<" | ">")`
+	Op    string          `[ @("<" "=" | ">" "=" | "<" (?! "<") | ">" (?! ">"))`
 	Right *ComparisonExpr `@@ ]`
 
 	Pos lexer.Position
@@ -184,8 +323,10 @@ type MulExpr struct {
 }
 
 type CastingExpr struct {
-	Type *Type       `[ "(" @@ ")" ]`
-	Expr *SizeOfExpr `@@`
+	// casts can be chained, e.g. (bool)(i64*)0
+	Type *Type        `( "(" @@ ")"`
+	Cast *CastingExpr `  @@`
+	Expr *SizeOfExpr  `| @@ )`
 
 	Pos lexer.Position
 }
@@ -225,22 +366,16 @@ type PostfixExprTick struct {
 	Pos lexer.Position
 }
 
+// AccessorExpr handles fields, indexes and calls in a single loop, so they can follow each other in any order,
+// e.g. s.cb(1, 2), p->xs[i], table[i](x) or (*fp)(x)
 type AccessorExpr struct {
-	Head *IndexExpr `@@`
-	Tail []struct {
-		Op    string `@("-" ">" | ".")`
-		Field string `@Ident`
-
-		Pos lexer.Position
-	} `@@*`
-
-	Pos lexer.Position
-}
-
-type IndexExpr struct {
 	Head *UnaryExpr `@@`
 	Tail []struct {
-		Index *Expr `'[' @@ ']'`
+		Op    string  `( @("-" ">" | ".")`
+		Field string  `  @Ident`
+		Index *Expr   `| '[' @@ ']'`
+		Call  bool    `| @"("`
+		Args  []*Expr `  ( @@ ( "," @@ )* )? ")" )`
 
 		Pos lexer.Position
 	} `@@*`
@@ -266,16 +401,21 @@ type FnCallExpr struct {
 type StructField struct {
 	Field string `"." @Ident`
 	Expr  *Expr  `"=" @@`
+
+	Pos lexer.Position
 }
 
 type StructExpr struct {
 	Alias        string         `@Ident`
-	StructFields []*StructField `"{" @@ ( "," @@ )* "," "}"`
+	StructFields []*StructField `"{" ( @@ ( "," @@ )* ","? )? "}"`
+
+	Pos lexer.Position
 }
 
 type PrimaryExpr struct {
 	// StructExpr must be before Ident, because StructExpr starts with Ident
 	Struct   *StructExpr `@@`
+	Null     bool        `| @"null"`
 	Variable string      `| @Ident`
 	Sign     string      `| @("+" | "-")?`
 	Number   string      `@Number`
@@ -300,14 +440,30 @@ type Declarator struct {
 	Pos lexer.Position
 }
 
+// Struct is also used for unions, their fields share the same memory
 type Struct struct {
-	Fields []*Declarator `"struct" "{" @@ ( "," @@ )* "," "}"`
+	Union  bool          `( @"union" | "struct" )`
+	Fields []*Declarator `"{" @@ ( "," @@ )* "," "}"`
+}
+
+// FuncType is the type of functions used as values, e.g. fn(i8*, i8*) -> i32
+type FuncType struct {
+	Params   []*Type `"fn" "(" ( @@ ( "," @@ )* )?`
+	Variadic bool    `@( "," "." "." "." )? ")"`
+	Return   *Type   `"-" ">" @@`
+}
+
+// ArrayLength is a number or an enum constant, e.g. [SIZE]i64
+type ArrayLength struct {
+	Number int    `@Number`
+	Const  string `| @Ident`
 }
 
 type Type struct {
-	Lengths []int `( "[" @Number "]" )*`
+	Lengths []*ArrayLength `( "[" @@ "]" )*`
 
-	Struct *Struct `( @@`
+	Struct *Struct   `( @@`
+	Func   *FuncType `| @@`
 	// must match lexer.go BasicType AND ast.Type
 	Basic string `| @("bool" | "void" | "i8" | "i16" | "i32" | "i64" | "u8" | "u16" | "u32" | "u64" | "f32" | "f64")`
 	Alias string `| @Ident )`
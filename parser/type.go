@@ -19,7 +19,18 @@ func (t *Type) Transform(scope ast.ScopeLike) *ast.Type {
 			})
 		}
 
-		typ = ast.NewTypeStruct(scope, t.Pos, fields...)
+		if t.Struct.Union {
+			typ = ast.NewTypeUnion(scope, t.Pos, fields...)
+		} else {
+			typ = ast.NewTypeStruct(scope, t.Pos, fields...)
+		}
+	} else if t.Func != nil {
+		params := []*ast.Type{}
+		for _, p := range t.Func.Params {
+			params = append(params, p.Transform(scope))
+		}
+
+		typ = ast.NewTypeFunc(scope, t.Pos, params, t.Func.Variadic, t.Func.Return.Transform(scope))
 	} else if t.Alias != "" {
 		typ = ast.NewTypeAlias(scope, t.Pos, t.Alias)
 	}
@@ -28,9 +39,27 @@ func (t *Type) Transform(scope ast.ScopeLike) *ast.Type {
 		typ = typ.NewPointer()
 	}
 
-	for i := 0; i < len(t.Lengths); i++ {
-		typ = typ.NewArray(t.Lengths[i])
+	// [2][4]i8 is an array of 2 [4]i8 arrays, so we start wrapping with the innermost length
+	for i := len(t.Lengths) - 1; i >= 0; i-- {
+		typ = t.Lengths[i].wrap(scope, typ)
 	}
 
 	return typ
 }
+
+// wrap returns an array of typ, a length given by an enum constant must be declared before it is used
+func (l *ArrayLength) wrap(scope ast.ScopeLike, typ *ast.Type) *ast.Type {
+	if l.Const == "" {
+		return typ.NewArray(l.Number)
+	}
+
+	length := 0
+	if c := scope.CurrentModule().FindConstant(l.Const); c != nil && c.Value != nil && c.Value.IsInt64() {
+		length = int(c.Value.Int64())
+	}
+
+	array := typ.NewArray(length)
+	array.Array().Const = l.Const
+
+	return array
+}
@@ -18,6 +18,9 @@ type Context struct {
 	parser    *parser.Parser
 	clangPath string
 	tmpFolder string
+
+	// Warnings of the last generated K program
+	Warnings []error
 }
 
 func NewContext() *Context {
@@ -79,6 +82,8 @@ func (c *Context) RunProgramK(src string, opts []Option) (string, error) {
 		return "", NewGenerateError(err, src)
 	}
 
+	c.Warnings = transformedAst.Warnings
+
 	bitCodeStr := bitCode.String()
 
 	// fmt.Println(bitCodeStr)
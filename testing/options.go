@@ -43,9 +43,9 @@ type OptionDeclare struct{ Declare []string }
 func Declare(declare string) *OptionDeclare { return &OptionDeclare{Declare: []string{declare}} }
 func DeclareMalloc() *OptionDeclare {
 	return &OptionDeclare{Declare: []string{
-		"i8* malloc(i64 size);",
-		"i8 free(i8* ptr);",
-		"i8* memset(i8* ptr, i8 val, i64 size);",
+		"void* malloc(i64 size);",
+		"void free(void* ptr);",
+		"void* memset(void* ptr, i8 val, i64 size);",
 	}}
 }
 func (o *OptionDeclare) Option() {}
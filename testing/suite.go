@@ -1,6 +1,8 @@
 package testing
 
 import (
+	"strings"
+
 	"github.com/llir/llvm/ir"
 	"github.com/stretchr/testify/suite"
 )
@@ -23,6 +25,31 @@ func (suite *CompilerSuite) EqualProgramK(src, expected string, opts ...Option)
 	suite.Equal(expected, result)
 }
 
+func (suite *CompilerSuite) WarningProgramK(src, contains string, opts ...Option) {
+	c := NewContext()
+	defer c.Destroy()
+
+	_, err := c.RunProgramK(src, opts)
+	suite.NoError(err)
+
+	warnings := []string{}
+	for _, w := range c.Warnings {
+		warnings = append(warnings, w.Error())
+	}
+
+	suite.Contains(strings.Join(warnings, "\n"), contains)
+}
+
+func (suite *CompilerSuite) NoWarningProgramK(src string, opts ...Option) {
+	c := NewContext()
+	defer c.Destroy()
+
+	_, err := c.RunProgramK(src, opts)
+	suite.NoError(err)
+
+	suite.Empty(c.Warnings)
+}
+
 func (suite *CompilerSuite) ErrorParseProgramK(src, contains string, opts ...Option) {
 	c := NewContext()
 	defer c.Destroy()
@@ -58,6 +85,16 @@ func (suite *CompilerSuite) EqualExprK(expr, format, expected string, opts ...Op
 	suite.EqualProgramK(src, expected, opts...)
 }
 
+func (suite *CompilerSuite) WarningExprK(expr, contains string, opts ...Option) {
+	src := ExprToProgramK(expr, `""`, opts)
+	suite.WarningProgramK(src, contains, opts...)
+}
+
+func (suite *CompilerSuite) NoWarningExprK(expr string, opts ...Option) {
+	src := ExprToProgramK(expr, `""`, opts)
+	suite.NoWarningProgramK(src, opts...)
+}
+
 func (suite *CompilerSuite) ErrorParseExprK(expr, contains string, opts ...Option) {
 	src := ExprToProgramK(expr, `""`, opts)
 	suite.ErrorParseProgramK(src, contains, opts...)